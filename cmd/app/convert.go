@@ -0,0 +1,58 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+
+	"biathlon/event"
+)
+
+func runConvert(args []string) error {
+	fs := newFlagSet("convert", "[-from text|csv|jsonl] [-to text|csv|jsonl] input output")
+	from := fs.String("from", "", "input format (detected by extension or content if empty)")
+	to := fs.String("to", "", "output format (detected by output extension if empty)")
+	fs.Parse(args)
+
+	if fs.NArg() != 2 {
+		return usageError(fs, "input and output are required")
+	}
+
+	inputPath, outputPath := fs.Arg(0), fs.Arg(1)
+
+	data, err := readInput(inputPath)
+	if err != nil {
+		return fmt.Errorf("reading events: %w", err)
+	}
+
+	decoder := event.DetectFormat(inputPath, data)
+	if *from != "" {
+		if decoder, err = event.LookupFormat(*from); err != nil {
+			return usageError(fs, "%v", err)
+		}
+	}
+
+	encoder := event.DetectFormat(outputPath, nil)
+	if *to != "" {
+		if encoder, err = event.LookupFormat(*to); err != nil {
+			return usageError(fs, "%v", err)
+		}
+	}
+
+	events, err := decoder.Decode(bytes.NewReader(data))
+	if err != nil {
+		return withCode(exitParse, fmt.Errorf("loading events: %w", err))
+	}
+
+	var out bytes.Buffer
+	if err := encoder.Encode(&out, events); err != nil {
+		return fmt.Errorf("encoding events: %w", err)
+	}
+
+	if err := writeOutput(outputPath, &out); err != nil {
+		return fmt.Errorf("writing events: %w", err)
+	}
+
+	fmt.Fprintf(os.Stderr, "Converted %d events from %s to %s\n", len(events), decoder.Name(), encoder.Name())
+	return nil
+}
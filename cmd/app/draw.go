@@ -0,0 +1,90 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+
+	"biathlon/draw"
+	"biathlon/event"
+	"biathlon/roster"
+)
+
+func runDraw(args []string) error {
+	fs := newFlagSet("draw", "[-seed 1] [-groups red,blue] [-format text|json] [-o startlist] [-events events] -config config.json -roster roster.csv")
+	configPath := fs.String("config", "", `configuration file ("-" for stdin)`)
+	rosterPath := fs.String("roster", "", "roster CSV with id, name and group columns")
+	seed := fs.Int64("seed", 1, "random seed")
+	groups := fs.String("groups", "", "comma-separated order of seeding groups (unlisted groups follow alphabetically)")
+	at := fs.String("at", "", "time of the registration and start time events (default: 30 minutes before the start)")
+	format := fs.String("format", "text", "start list format: text or json")
+	outputPath := fs.String("o", "-", `output start list file ("-" for stdout)`)
+	eventsPath := fs.String("events", "", "output file for the registration and start time events")
+	to := fs.String("to", "", "events format (detected by events extension if empty)")
+	fs.Parse(args)
+
+	switch {
+	case fs.NArg() > 0:
+		return usageError(fs, "unexpected arguments")
+	case *configPath == "" || *rosterPath == "":
+		return usageError(fs, "config and roster are required")
+	case *format != "text" && *format != "json":
+		return usageError(fs, "unknown format %q", *format)
+	}
+
+	cfg, err := loadConfig(*configPath)
+	if err != nil {
+		return err
+	}
+	r, err := roster.LoadFromFile(*rosterPath)
+	if err != nil {
+		return withCode(exitConfig, fmt.Errorf("loading roster: %w", err))
+	}
+
+	opts := draw.Options{Seed: *seed}
+	if *groups != "" {
+		opts.Groups = strings.Split(*groups, ",")
+	}
+	entries, err := draw.Draw(cfg, r, opts)
+	if err != nil {
+		return withCode(exitConfig, fmt.Errorf("drawing start list: %w", err))
+	}
+
+	if *eventsPath != "" {
+		events, err := draw.Events(cfg, entries, *at)
+		if err != nil {
+			return usageError(fs, "%v", err)
+		}
+
+		encoder := event.DetectFormat(*eventsPath, nil)
+		if *to != "" {
+			if encoder, err = event.LookupFormat(*to); err != nil {
+				return usageError(fs, "%v", err)
+			}
+		}
+
+		var out bytes.Buffer
+		if err := encoder.Encode(&out, events); err != nil {
+			return fmt.Errorf("encoding events: %w", err)
+		}
+		if err := writeOutput(*eventsPath, &out); err != nil {
+			return fmt.Errorf("writing events: %w", err)
+		}
+	}
+
+	var startList string
+	if *format == "json" {
+		if startList, err = draw.GenerateStartListJSON(entries); err != nil {
+			return fmt.Errorf("generating start list: %w", err)
+		}
+	} else {
+		startList = draw.GenerateStartList(entries)
+	}
+	if err := writeOutput(*outputPath, strings.NewReader(startList)); err != nil {
+		return fmt.Errorf("writing start list: %w", err)
+	}
+
+	fmt.Fprintf(os.Stderr, "Drew %d competitors\n", len(entries))
+	return nil
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"io"
+	"os"
+)
+
+// readInput читает файл или стандартный ввод, если путь равен "-"
+func readInput(path string) ([]byte, error) {
+	if path == "-" {
+		return io.ReadAll(os.Stdin)
+	}
+	return os.ReadFile(path)
+}
+
+// writeOutput пишет в файл или в стандартный вывод, если путь равен "-"
+func writeOutput(path string, r io.Reader) error {
+	if path == "-" {
+		_, err := io.Copy(os.Stdout, r)
+		return err
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = io.Copy(f, r)
+	return err
+}
@@ -1,65 +1,173 @@
 package main
 
 import (
+	"errors"
+	"flag"
 	"fmt"
 	"os"
-	"path/filepath"
+	"strings"
 
 	"biathlon/config"
 	"biathlon/event"
 	"biathlon/race"
 )
 
+const program = "go run ./cmd/app"
+
+// Коды завершения
+const (
+	exitFailure = 1 // ошибки ввода-вывода и прочие сбои
+	exitUsage   = 2
+	exitConfig  = 3
+	exitParse   = 4
+	exitRules   = 5
+)
+
+// exitError несёт код завершения, с которым main должен выйти
+type exitError struct {
+	code int
+	err  error
+}
+
+func (e *exitError) Error() string { return e.err.Error() }
+
+func (e *exitError) Unwrap() error { return e.err }
+
+func withCode(code int, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &exitError{code: code, err: err}
+}
+
+func exitCode(err error) int {
+	var e *exitError
+	if errors.As(err, &e) {
+		return e.code
+	}
+	return exitFailure
+}
+
+type command struct {
+	name    string
+	summary string
+	run     func(args []string) error
+}
+
+var commands = []command{
+	{"process", "process events and write the log and reports", runProcess},
+	{"validate", "check config and events without writing results", runValidate},
+	{"report", "write a single report to a file or stdout", runReport},
+	{"season", "compute cumulative standings of a race series", runSeason},
+	{"watch", "follow a growing events file and keep the log and report up to date", runWatch},
+	{"draw", "draw the start list and generate registration and start time events", runDraw},
+	{"simulate", "generate a realistic stream of incoming events", runSimulate},
+	{"convert", "convert events between text, csv and jsonl", runConvert},
+	{"serve", "serve races over HTTP", runServe},
+}
+
 func main() {
-	if len(os.Args) != 4 {
-		fmt.Println("Usage: ./cmd/app/main.go config.json events output_prefix")
-		return
+	os.Exit(run(os.Args[1:]))
+}
+
+func run(args []string) int {
+	if len(args) == 0 || args[0] == "-h" || args[0] == "-help" || args[0] == "help" {
+		usage()
+		if len(args) == 0 {
+			return exitUsage
+		}
+		return 0
 	}
 
-	configPath := os.Args[1]
-	eventsPath := os.Args[2]
-	outputPrefix := os.Args[3]
+	// Вызов без подкоманды (config.json events output_prefix) по-прежнему означает process
+	runCmd := runProcess
+	cmdArgs := args
+	for _, cmd := range commands {
+		if cmd.name == args[0] {
+			runCmd, cmdArgs = cmd.run, args[1:]
+			break
+		}
+	}
 
-	cfg, err := config.LoadFromFile(configPath)
-	if err != nil {
-		fmt.Printf("Error loading configuration: %v\n", err)
-		return
+	if err := runCmd(cmdArgs); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return exitCode(err)
 	}
+	return 0
+}
 
-	raceCtrl := race.NewController(cfg)
+func usage() {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Usage: %s <command> [flags]\n\nCommands:\n", program)
+	for _, cmd := range commands {
+		fmt.Fprintf(&b, "  %-9s %s\n", cmd.name, cmd.summary)
+	}
+	fmt.Fprintf(&b, "\nUse \"-\" instead of a file name to read from stdin or write to stdout.\n")
+	fmt.Fprintf(&b, "Exit codes: %d failure, %d usage, %d config errors, %d parse errors, %d rule violations\n",
+		exitFailure, exitUsage, exitConfig, exitParse, exitRules)
+	fmt.Fprint(os.Stderr, b.String())
+}
+
+// newFlagSet создаёт набор флагов подкоманды с единообразной справкой
+func newFlagSet(name, synopsis string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: %s %s %s\n", program, name, synopsis)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+func usageError(fs *flag.FlagSet, format string, args ...any) error {
+	fs.Usage()
+	return withCode(exitUsage, fmt.Errorf(format, args...))
+}
 
-	events, err := event.LoadFromFile(eventsPath)
+func loadConfig(path string) (*config.Config, error) {
+	data, err := readInput(path)
 	if err != nil {
-		fmt.Printf("Error loading events: %v\n", err)
-		return
+		return nil, withCode(exitConfig, fmt.Errorf("loading configuration: %w", err))
 	}
 
-	outputLog, err := raceCtrl.ProcessEvents(events)
+	cfg, err := config.Parse(data)
 	if err != nil {
-		fmt.Printf("Error processing events: %v\n", err)
-		return
+		return nil, withCode(exitConfig, fmt.Errorf("loading configuration: %w", err))
 	}
 
-	outputDir := filepath.Dir(outputPrefix)
-	if outputDir != "" && outputDir != "." {
-		if err := os.MkdirAll(outputDir, 0755); err != nil {
-			fmt.Printf("Error creating output directory: %v\n", err)
-			return
-		}
+	if errs := cfg.Validate(); len(errs) > 0 {
+		return nil, withCode(exitConfig, fmt.Errorf("invalid configuration: %w", errors.Join(errs...)))
+	}
+
+	return cfg, nil
+}
+
+// process прогоняет конфигурацию и события через контроллер гонки и возвращает текстовый лог
+func process(configPath, eventsPath string) (*race.Controller, string, error) {
+	cfg, err := loadConfig(configPath)
+	if err != nil {
+		return nil, "", err
+	}
+
+	data, err := readInput(eventsPath)
+	if err != nil {
+		return nil, "", fmt.Errorf("loading events: %w", err)
 	}
 
-	logFile := outputPrefix + "_log.txt"
-	if err := os.WriteFile(logFile, []byte(outputLog), 0644); err != nil {
-		fmt.Printf("Error writing output log: %v\n", err)
-		return
+	return processData(cfg, eventsPath, data)
+}
+
+func processData(cfg *config.Config, eventsPath string, data []byte) (*race.Controller, string, error) {
+	events, err := event.Decode(eventsPath, data)
+	if err != nil {
+		return nil, "", withCode(exitParse, fmt.Errorf("loading events: %w", err))
 	}
 
-	report := raceCtrl.GenerateReport()
-	reportFile := outputPrefix + "_report.txt"
-	if err := os.WriteFile(reportFile, []byte(report), 0644); err != nil {
-		fmt.Printf("Error writing final report: %v\n", err)
-		return
+	raceCtrl := race.NewController(cfg)
+
+	outputLog, err := raceCtrl.ProcessEvents(events)
+	if err != nil {
+		return nil, "", fmt.Errorf("processing events: %w", err)
 	}
 
-	fmt.Println("Processing completed successfully!")
+	return raceCtrl, outputLog, nil
 }
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "regenerate golden files in testdata")
+
+// TestGolden прогоняет полный конвейер для каждого каталога testdata/*
+// (config.json + events) и сравнивает результат с эталонными файлами expected_*.txt.
+// Для обновления эталонов: go test ./cmd/app -update
+func TestGolden(t *testing.T) {
+	configs, err := filepath.Glob(filepath.Join("testdata", "*", "config.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(configs) == 0 {
+		t.Fatal("No golden test cases found")
+	}
+
+	for _, configPath := range configs {
+		dir := filepath.Dir(configPath)
+		t.Run(filepath.Base(dir), func(t *testing.T) {
+			raceCtrl, outputLog, err := process(configPath, filepath.Join(dir, "events"))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			checkGolden(t, filepath.Join(dir, "expected_log.txt"), outputLog)
+			checkGolden(t, filepath.Join(dir, "expected_report.txt"), raceCtrl.GenerateReport())
+
+			splits, err := raceCtrl.GenerateSplitsReport()
+			if err != nil {
+				t.Fatal(err)
+			}
+			checkGolden(t, filepath.Join(dir, "expected_splits.txt"), splits)
+			checkGolden(t, filepath.Join(dir, "expected_analytics.txt"), raceCtrl.GenerateAnalyticsReport())
+			checkGolden(t, filepath.Join(dir, "expected_segments.txt"), raceCtrl.GenerateSegmentsReport())
+			checkGolden(t, filepath.Join(dir, "expected_shooting.txt"), raceCtrl.GenerateShootingReport())
+			checkGolden(t, filepath.Join(dir, "expected_stages.txt"), raceCtrl.GenerateStagesReport())
+			checkGolden(t, filepath.Join(dir, "expected_positions.txt"), raceCtrl.GeneratePositionsReport())
+			checkGolden(t, filepath.Join(dir, "expected_anomalies.txt"), raceCtrl.GenerateAnomaliesReport())
+		})
+	}
+}
+
+func checkGolden(t *testing.T, path, got string) {
+	t.Helper()
+
+	if *update {
+		if err := os.WriteFile(path, []byte(got), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("%v (run with -update to create it)", err)
+	}
+
+	if diff := diffLines(string(want), got); diff != "" {
+		t.Errorf("%s mismatch (-want +got):\n%s", path, diff)
+	}
+}
+
+// diffLines возвращает построчные различия с номерами строк
+func diffLines(want, got string) string {
+	wantLines := strings.Split(want, "\n")
+	gotLines := strings.Split(got, "\n")
+
+	var diff strings.Builder
+	for i := 0; i < max(len(wantLines), len(gotLines)); i++ {
+		var w, g string
+		if i < len(wantLines) {
+			w = wantLines[i]
+		}
+		if i < len(gotLines) {
+			g = gotLines[i]
+		}
+		if w != g {
+			fmt.Fprintf(&diff, "%d:\n-%s\n+%s\n", i+1, w, g)
+		}
+	}
+	return diff.String()
+}
+
+func TestExitCodes(t *testing.T) {
+	dir := t.TempDir()
+	badConfig := filepath.Join(dir, "bad.json")
+	badEvents := filepath.Join(dir, "bad_events")
+	os.WriteFile(badConfig, []byte(`{"laps": 0}`), 0644)
+	os.WriteFile(badEvents, []byte("[10:00:00.000] 1\n"), 0644)
+
+	config, events := filepath.Join("testdata", "readme", "config.json"), filepath.Join("testdata", "readme", "events")
+	roster := filepath.Join(dir, "roster.csv")
+	os.WriteFile(roster, []byte("id,name,nation,club\n1,Anna,NOR,Oslo\n"), 0644)
+	juryConfig, juryEvents := filepath.Join("testdata", "jury", "config.json"), filepath.Join("testdata", "jury", "events")
+
+	tests := []struct {
+		name string
+		args []string
+		want int
+	}{
+		{"no command", nil, exitUsage},
+		{"legacy process", []string{config, events, filepath.Join(dir, "legacy")}, 0},
+		{"process", []string{"process", "-config", config, "-events", events, "-out", filepath.Join(dir, "out")}, 0},
+		{"missing flags", []string{"process", "-config", config}, exitUsage},
+		{"config error", []string{"process", "-config", badConfig, "-events", events, "-out", filepath.Join(dir, "out")}, exitConfig},
+		{"parse error", []string{"process", "-config", config, "-events", badEvents, "-out", filepath.Join(dir, "out")}, exitParse},
+		{"rule violations", []string{"process", "-strict", "-config", juryConfig, "-events", juryEvents, "-out", filepath.Join(dir, "jury")}, exitRules},
+		{"validate config", []string{"validate", badConfig, events}, exitConfig},
+		{"validate parse", []string{"validate", config, badEvents}, exitParse},
+		{"validate rules", []string{"validate", "-config", juryConfig, "-events", juryEvents}, exitRules},
+		{"unknown report", []string{"report", "-type", "unknown", "-config", config, "-events", events}, exitUsage},
+		{"teams html", []string{"report", "-type", "teams", "-format", "html", "-roster", roster, "-config", config, "-events", events}, exitUsage},
+		{"teams sheet", []string{"report", "-type", "teams", "-format", "sheet", "-roster", roster, "-config", config, "-events", events}, exitUsage},
+		{"missing file", []string{"report", "-config", config, "-events", filepath.Join(dir, "missing")}, exitFailure},
+	}
+
+	for _, tt := range tests {
+		if got := run(tt.args); got != tt.want {
+			t.Errorf("%s: exit code %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,228 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"biathlon/config"
+	"biathlon/event"
+	"biathlon/lint"
+	"biathlon/race"
+	"biathlon/report"
+	"biathlon/store"
+)
+
+func runProcess(args []string) error {
+	fs := newFlagSet("process", "-config config.json -events events -out output_prefix\n"+
+		"  (or the legacy form: [flags] config.json events output_prefix)")
+	configPath := fs.String("config", "", `configuration file ("-" for stdin)`)
+	eventsPath := fs.String("events", "", `events file in text, csv or jsonl format ("-" for stdin)`)
+	outputPrefix := fs.String("out", "", `prefix of output files; "-" writes only the log to stdout`)
+	logFormat := fs.String("log-format", "text", "output log format: text or jsonl")
+	reportFormat := fs.String("report-format", "text", "final report format: text (_report.txt), json (_report.json) or html (_report.html)")
+	splits := fs.String("splits", "", `write standings at timing points to output_prefix_splits.txt: "all" or comma-separated names like "shooting 2,lap 1"`)
+	analytics := fs.Bool("analytics", false, "write range, shooting and ski time rankings to output_prefix_analytics.txt")
+	segments := fs.Bool("segments", false, "write ski speed per course segment to output_prefix_segments.txt (requires course in config)")
+	shooting := fs.Bool("shooting", false, "write prone and standing accuracy to output_prefix_shooting.txt (requires shooting order in config)")
+	stages := fs.Bool("stages", false, "write positions and position changes after each shooting stage to output_prefix_stages.txt")
+	positions := fs.Bool("positions", false, "write position history and overtakes of a mass start or pursuit to output_prefix_positions.txt")
+	anomalies := fs.Bool("anomalies", false, "write timing anomalies to output_prefix_anomalies.txt")
+	strict := fs.Bool("strict", false, "check events against the race rules and fail on violations")
+	storePath := fs.String("store", "", "results store file to save the race to")
+	raceName := fs.String("race", "", "name of the race in the store (required with -store)")
+	fs.Parse(args)
+
+	legacy := *configPath == "" && *eventsPath == "" && *outputPrefix == "" && fs.NArg() == 3
+	if legacy {
+		*configPath, *eventsPath, *outputPrefix = fs.Arg(0), fs.Arg(1), fs.Arg(2)
+	}
+
+	switch {
+	case fs.NArg() > 0 && !legacy:
+		return usageError(fs, "unexpected arguments: %s", strings.Join(fs.Args(), " "))
+	case *configPath == "" || *eventsPath == "" || *outputPrefix == "":
+		return usageError(fs, "config, events and output prefix are required")
+	case *configPath == "-" && *eventsPath == "-":
+		return usageError(fs, "config and events cannot both be read from stdin")
+	case *logFormat != "text" && *logFormat != "jsonl":
+		return usageError(fs, "unknown log format %q", *logFormat)
+	case *reportFormat != "text" && *reportFormat != "json" && *reportFormat != "html":
+		return usageError(fs, "unknown report format %q", *reportFormat)
+	case *storePath != "" && *raceName == "":
+		return usageError(fs, "race name is required to save to the store")
+	case *outputPrefix == "-" && (*splits != "" || *analytics || *segments || *shooting || *stages || *positions || *anomalies):
+		return usageError(fs, `reports cannot be written to stdout, use the report command`)
+	}
+
+	cfg, err := loadConfig(*configPath)
+	if err != nil {
+		return err
+	}
+
+	data, err := readInput(*eventsPath)
+	if err != nil {
+		return fmt.Errorf("loading events: %w", err)
+	}
+
+	raceCtrl, outputLog, err := processData(cfg, *eventsPath, data)
+	if err != nil {
+		return err
+	}
+
+	if *storePath != "" {
+		if err := saveRace(*storePath, *raceName, raceCtrl, *eventsPath, data); err != nil {
+			return err
+		}
+	}
+
+	if *logFormat == "jsonl" {
+		if outputLog, err = raceCtrl.JSONLog(); err != nil {
+			return fmt.Errorf("encoding output log: %w", err)
+		}
+	}
+
+	// Без префикса файлов в стандартный вывод пишется только лог
+	if *outputPrefix == "-" {
+		if err := writeOutput("-", strings.NewReader(outputLog)); err != nil {
+			return fmt.Errorf("writing output log: %w", err)
+		}
+		return checkRules(*strict, cfg, *configPath, *eventsPath, data)
+	}
+
+	outputDir := filepath.Dir(*outputPrefix)
+	if outputDir != "" && outputDir != "." {
+		if err := os.MkdirAll(outputDir, 0755); err != nil {
+			return fmt.Errorf("creating output directory: %w", err)
+		}
+	}
+
+	logFile := *outputPrefix + "_log.txt"
+	if *logFormat == "jsonl" {
+		logFile = *outputPrefix + "_log.jsonl"
+	}
+	if err := os.WriteFile(logFile, []byte(outputLog), 0644); err != nil {
+		return fmt.Errorf("writing output log: %w", err)
+	}
+
+	finalReport, reportFile := raceCtrl.GenerateReport(), *outputPrefix+"_report.txt"
+	switch *reportFormat {
+	case "json":
+		reportFile = *outputPrefix + "_report.json"
+		finalReport, err = raceCtrl.GenerateResultsJSON()
+	case "html":
+		reportFile = *outputPrefix + "_report.html"
+		finalReport, err = raceCtrl.GenerateResultsHTML(report.HTMLOptions{})
+	}
+	if err != nil {
+		return fmt.Errorf("encoding final report: %w", err)
+	}
+	if err := os.WriteFile(reportFile, []byte(finalReport), 0644); err != nil {
+		return fmt.Errorf("writing final report: %w", err)
+	}
+
+	if *splits != "" {
+		var names []string
+		if *splits != "all" {
+			names = strings.Split(*splits, ",")
+		}
+
+		splitsReport, err := raceCtrl.GenerateSplitsReport(names...)
+		if err != nil {
+			return fmt.Errorf("generating splits: %w", err)
+		}
+
+		if err := os.WriteFile(*outputPrefix+"_splits.txt", []byte(splitsReport), 0644); err != nil {
+			return fmt.Errorf("writing splits: %w", err)
+		}
+	}
+
+	if *analytics {
+		if err := os.WriteFile(*outputPrefix+"_analytics.txt", []byte(raceCtrl.GenerateAnalyticsReport()), 0644); err != nil {
+			return fmt.Errorf("writing analytics: %w", err)
+		}
+	}
+
+	if *segments {
+		if err := os.WriteFile(*outputPrefix+"_segments.txt", []byte(raceCtrl.GenerateSegmentsReport()), 0644); err != nil {
+			return fmt.Errorf("writing segments: %w", err)
+		}
+	}
+
+	if *shooting {
+		if err := os.WriteFile(*outputPrefix+"_shooting.txt", []byte(raceCtrl.GenerateShootingReport()), 0644); err != nil {
+			return fmt.Errorf("writing shooting statistics: %w", err)
+		}
+	}
+
+	if *stages {
+		if err := os.WriteFile(*outputPrefix+"_stages.txt", []byte(raceCtrl.GenerateStagesReport()), 0644); err != nil {
+			return fmt.Errorf("writing stages: %w", err)
+		}
+	}
+
+	if *positions {
+		if err := os.WriteFile(*outputPrefix+"_positions.txt", []byte(raceCtrl.GeneratePositionsReport()), 0644); err != nil {
+			return fmt.Errorf("writing positions: %w", err)
+		}
+	}
+
+	if *anomalies {
+		if err := os.WriteFile(*outputPrefix+"_anomalies.txt", []byte(raceCtrl.GenerateAnomaliesReport()), 0644); err != nil {
+			return fmt.Errorf("writing anomalies: %w", err)
+		}
+	}
+
+	if err := checkRules(*strict, cfg, *configPath, *eventsPath, data); err != nil {
+		return err
+	}
+
+	fmt.Fprintln(os.Stderr, "Processing completed successfully!")
+	return nil
+}
+
+// saveRace сохраняет конфигурацию, события и результаты гонки, заменяя прежнюю версию
+func saveRace(storePath, name string, raceCtrl *race.Controller, eventsPath string, data []byte) error {
+	events, err := event.Decode(eventsPath, data)
+	if err != nil {
+		return withCode(exitParse, fmt.Errorf("loading events: %w", err))
+	}
+
+	st, err := openStore(storePath)
+	if err != nil {
+		return err
+	}
+	defer st.Close()
+
+	err = st.PutRace(&store.Race{
+		Name:        name,
+		Config:      raceCtrl.Config,
+		Events:      events,
+		Competitors: raceCtrl.Competitors,
+		Results:     raceCtrl.Results(),
+	})
+	if err != nil {
+		return fmt.Errorf("saving race %q: %w", name, err)
+	}
+	return nil
+}
+
+// checkRules в строгом режиме выводит нарушения правил в stderr; результаты к этому моменту уже записаны
+func checkRules(strict bool, cfg *config.Config, configPath, eventsPath string, data []byte) error {
+	if !strict {
+		return nil
+	}
+
+	violations := 0
+	for _, issue := range lint.Lint(configPath, cfg, eventsPath, data) {
+		if issue.Severity == race.SeverityError {
+			fmt.Fprintln(os.Stderr, issue)
+			violations++
+		}
+	}
+	if violations > 0 {
+		return withCode(exitRules, fmt.Errorf("%d rule violation(s) in %s", violations, eventsPath))
+	}
+	return nil
+}
@@ -0,0 +1,170 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"biathlon/race"
+	"biathlon/report"
+	"biathlon/roster"
+)
+
+// Отчёты, которые может вывести подкоманда report
+var reportTypes = map[string]func(ctrl *race.Controller, splits []string) (string, error){
+	"final": func(ctrl *race.Controller, _ []string) (string, error) {
+		return ctrl.GenerateReport(), nil
+	},
+	"splits": func(ctrl *race.Controller, splits []string) (string, error) {
+		return ctrl.GenerateSplitsReport(splits...)
+	},
+	"analytics": func(ctrl *race.Controller, _ []string) (string, error) {
+		return ctrl.GenerateAnalyticsReport(), nil
+	},
+	"segments": func(ctrl *race.Controller, _ []string) (string, error) {
+		return ctrl.GenerateSegmentsReport(), nil
+	},
+	"shooting": func(ctrl *race.Controller, _ []string) (string, error) {
+		return ctrl.GenerateShootingReport(), nil
+	},
+	"stages": func(ctrl *race.Controller, _ []string) (string, error) {
+		return ctrl.GenerateStagesReport(), nil
+	},
+	"positions": func(ctrl *race.Controller, _ []string) (string, error) {
+		return ctrl.GeneratePositionsReport(), nil
+	},
+	"anomalies": func(ctrl *race.Controller, _ []string) (string, error) {
+		return ctrl.GenerateAnomaliesReport(), nil
+	},
+}
+
+// jsonReports - отчёты, доступные в формате JSON
+var jsonReports = map[string]func(ctrl *race.Controller) (string, error){
+	"final":     (*race.Controller).GenerateResultsJSON,
+	"positions": (*race.Controller).GeneratePositionsJSON,
+}
+
+func runReport(args []string) error {
+	fs := newFlagSet("report", "[-type final] [-format text|json|html|sheet] (-config config.json -events events | -store races.jsonl -race name) [-o output]")
+	configPath := fs.String("config", "", `configuration file ("-" for stdin)`)
+	eventsPath := fs.String("events", "", `events file ("-" for stdin)`)
+	outputPath := fs.String("o", "-", `output file ("-" for stdout)`)
+	reportType := fs.String("type", "final", "report type: final, splits, analytics, segments, shooting, stages, positions, anomalies or teams")
+	format := fs.String("format", "text", "output format: text, json (final, positions and teams reports), html or sheet (final report)")
+	title := fs.String("title", "", "page title of the html report and the sheet (overrides info.name in config)")
+	splits := fs.String("splits", "", `comma-separated timing point names for the splits report, all by default`)
+	storePath := fs.String("store", "", "results store file to read the race from instead of config and events")
+	raceName := fs.String("race", "", "name of the race in the store")
+	rosterPath := fs.String("roster", "", "roster CSV with id, name, nation and club columns (required for teams, adds names to html and sheet)")
+	teamSize := fs.Int("team-size", 3, "number of best athletes counted for a team")
+	teamBy := fs.String("team-by", report.TeamByNation, "team of an athlete: nation or club")
+	teamScoring := fs.String("team-scoring", report.TeamScoringTime, "team score: sum of times or sum of World Cup points")
+	fs.Parse(args)
+
+	generate, ok := reportTypes[*reportType]
+	generateJSON, hasJSON := jsonReports[*reportType]
+	teams := *reportType == "teams"
+	switch {
+	case fs.NArg() > 0:
+		return usageError(fs, "unexpected arguments")
+	case *storePath != "" && (*raceName == "" || *configPath != "" || *eventsPath != ""):
+		return usageError(fs, "use either -store with -race or -config with -events")
+	case *storePath == "" && (*configPath == "" || *eventsPath == ""):
+		return usageError(fs, "config and events are required")
+	case *configPath == "-" && *eventsPath == "-":
+		return usageError(fs, "config and events cannot both be read from stdin")
+	case !ok && !teams:
+		return usageError(fs, "unknown report type %q", *reportType)
+	case *format != "text" && *format != "json" && *format != "html" && *format != "sheet":
+		return usageError(fs, "unknown format %q", *format)
+	case teams && *format != "text" && *format != "json":
+		return usageError(fs, "teams report is available in text and json formats only")
+	case (*format == "html" || *format == "sheet") && *reportType != "final":
+		return usageError(fs, "%s format is available for the final report only", *format)
+	case *format == "json" && !hasJSON && !teams:
+		return usageError(fs, "json format is available for the final, positions and teams reports only")
+	case teams && *rosterPath == "":
+		return usageError(fs, "roster is required for the teams report")
+	case teams && *teamSize <= 0:
+		return usageError(fs, "team size must be positive")
+	case teams && *teamBy != report.TeamByNation && *teamBy != report.TeamByClub:
+		return usageError(fs, "unknown team attribute %q", *teamBy)
+	case teams && *teamScoring != report.TeamScoringTime && *teamScoring != report.TeamScoringPoints:
+		return usageError(fs, "unknown team scoring %q", *teamScoring)
+	}
+
+	var raceCtrl *race.Controller
+	var err error
+	if *storePath != "" {
+		raceCtrl, err = loadRace(*storePath, *raceName)
+	} else {
+		raceCtrl, _, err = process(*configPath, *eventsPath)
+	}
+	if err != nil {
+		return err
+	}
+
+	var names []string
+	if *splits != "" {
+		names = strings.Split(*splits, ",")
+	}
+
+	var out string
+	switch {
+	case teams:
+		var r roster.Roster
+		if r, err = roster.LoadFromFile(*rosterPath); err != nil {
+			return withCode(exitConfig, fmt.Errorf("loading roster: %w", err))
+		}
+		opts := report.TeamOptions{Size: *teamSize, By: *teamBy, Scoring: *teamScoring, Points: report.WorldCupPoints}
+		standings := report.TeamClassification(raceCtrl.Results(), r, opts)
+		if *format == "json" {
+			out, err = report.GenerateTeamJSON(standings)
+		} else {
+			out = report.GenerateTeamReport(standings, opts)
+		}
+	case *format == "json":
+		out, err = generateJSON(raceCtrl)
+	case *format == "html" || *format == "sheet":
+		opts := report.HTMLOptions{Title: *title}
+		if *rosterPath != "" {
+			if opts.Roster, err = roster.LoadFromFile(*rosterPath); err != nil {
+				return withCode(exitConfig, fmt.Errorf("loading roster: %w", err))
+			}
+		}
+		if *format == "sheet" {
+			out, err = raceCtrl.GenerateResultSheet(opts)
+		} else {
+			out, err = raceCtrl.GenerateResultsHTML(opts)
+		}
+	default:
+		out, err = generate(raceCtrl, names)
+	}
+	if err != nil {
+		return fmt.Errorf("generating %s report: %w", *reportType, err)
+	}
+
+	if err := writeOutput(*outputPath, strings.NewReader(out)); err != nil {
+		return fmt.Errorf("writing %s report: %w", *reportType, err)
+	}
+	return nil
+}
+
+// loadRace пересчитывает сохранённую гонку по её конфигурации и событиям
+func loadRace(storePath, name string) (*race.Controller, error) {
+	st, err := openStore(storePath)
+	if err != nil {
+		return nil, err
+	}
+	defer st.Close()
+
+	rc, err := st.Race(name)
+	if err != nil {
+		return nil, fmt.Errorf("loading race %q: %w", name, err)
+	}
+
+	raceCtrl := race.NewController(rc.Config)
+	if _, err := raceCtrl.ProcessEvents(rc.Events); err != nil {
+		return nil, fmt.Errorf("processing events: %w", err)
+	}
+	return raceCtrl, nil
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"biathlon/report"
+	"biathlon/season"
+)
+
+func runSeason(args []string) error {
+	fs := newFlagSet("season", "[-format text|json] [-store races.jsonl] -season season.json [-o output]")
+	seasonPath := fs.String("season", "", "season description: points table, dropped results, roster and races")
+	storePath := fs.String("store", "", "results store for races listed without config and events")
+	format := fs.String("format", "text", "output format: text or json")
+	outputPath := fs.String("o", "-", `output file ("-" for stdout)`)
+	fs.Parse(args)
+
+	switch {
+	case fs.NArg() > 0:
+		return usageError(fs, "unexpected arguments")
+	case *seasonPath == "":
+		return usageError(fs, "season is required")
+	case *format != "text" && *format != "json":
+		return usageError(fs, "unknown format %q", *format)
+	}
+
+	s, err := season.Load(*seasonPath)
+	if err != nil {
+		return withCode(exitConfig, fmt.Errorf("loading season: %w", err))
+	}
+
+	var source season.ResultsSource
+	if *storePath != "" {
+		st, err := openStore(*storePath)
+		if err != nil {
+			return err
+		}
+		defer st.Close()
+
+		source = func(name string) ([]report.Result, error) {
+			rc, err := st.Race(name)
+			if err != nil {
+				return nil, err
+			}
+			return rc.Results, nil
+		}
+	}
+
+	races, err := s.LoadResults(source)
+	if err != nil {
+		return err
+	}
+
+	out := s.GenerateReport(races)
+	if *format == "json" {
+		if out, err = s.GenerateJSON(races); err != nil {
+			return fmt.Errorf("encoding standings: %w", err)
+		}
+	}
+
+	if err := writeOutput(*outputPath, strings.NewReader(out)); err != nil {
+		return fmt.Errorf("writing standings: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+
+	"biathlon/server"
+	"biathlon/store"
+)
+
+func openStore(path string) (store.Store, error) {
+	if path == "" {
+		return store.NewMemoryStore(), nil
+	}
+
+	st, err := store.OpenFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("opening store: %w", err)
+	}
+	return st, nil
+}
+
+func runServe(args []string) error {
+	fs := newFlagSet("serve", "[-addr :8080] [-store races.jsonl]")
+	addr := fs.String("addr", ":8080", "address to listen on")
+	storePath := fs.String("store", "", "results store file; races are kept in memory only if empty")
+	fs.Parse(args)
+
+	if fs.NArg() > 0 {
+		return usageError(fs, "unexpected arguments")
+	}
+
+	st, err := openStore(*storePath)
+	if err != nil {
+		return err
+	}
+	defer st.Close()
+
+	fmt.Fprintf(os.Stderr, "Listening on %s\n", *addr)
+	return http.ListenAndServe(*addr, server.New(st).Handler())
+}
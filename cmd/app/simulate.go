@@ -0,0 +1,76 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+
+	"biathlon/event"
+	"biathlon/simulate"
+)
+
+func runSimulate(args []string) error {
+	defaults := simulate.DefaultOptions()
+
+	fs := newFlagSet("simulate", "[-n 30] [-seed 1] [-to text|csv|jsonl] -config config.json [-o output] (or: config.json output)")
+	configPath := fs.String("config", "", `configuration file ("-" for stdin)`)
+	outputPath := fs.String("o", "", `output events file ("-" for stdout, the default)`)
+	competitors := fs.Int("n", defaults.Competitors, "number of competitors")
+	seed := fs.Int64("seed", defaults.Seed, "random seed")
+	speed := fs.Float64("speed", defaults.MeanSpeed, "mean ski speed, m/s")
+	speedStdDev := fs.Float64("speed-stddev", defaults.SpeedStdDev, "ski speed standard deviation between competitors, m/s")
+	hit := fs.Float64("hit", defaults.HitProbability, "probability of hitting a target")
+	dnf := fs.Float64("dnf", defaults.DNFProbability, "probability of not finishing")
+	noShow := fs.Float64("no-show", defaults.NoShowProbability, "probability of not starting")
+	to := fs.String("to", "", "output format (detected by output extension if empty)")
+	fs.Parse(args)
+
+	if *configPath == "" && *outputPath == "" && fs.NArg() == 2 {
+		*configPath, *outputPath = fs.Arg(0), fs.Arg(1)
+	} else if fs.NArg() > 0 {
+		return usageError(fs, "unexpected arguments")
+	}
+	if *configPath == "" {
+		return usageError(fs, "config is required")
+	}
+	if *outputPath == "" {
+		*outputPath = "-"
+	}
+
+	cfg, err := loadConfig(*configPath)
+	if err != nil {
+		return err
+	}
+
+	events, err := simulate.Generate(cfg, simulate.Options{
+		Competitors:       *competitors,
+		Seed:              *seed,
+		MeanSpeed:         *speed,
+		SpeedStdDev:       *speedStdDev,
+		HitProbability:    *hit,
+		DNFProbability:    *dnf,
+		NoShowProbability: *noShow,
+	})
+	if err != nil {
+		return fmt.Errorf("generating events: %w", err)
+	}
+
+	encoder := event.DetectFormat(*outputPath, nil)
+	if *to != "" {
+		if encoder, err = event.LookupFormat(*to); err != nil {
+			return usageError(fs, "%v", err)
+		}
+	}
+
+	var out bytes.Buffer
+	if err := encoder.Encode(&out, events); err != nil {
+		return fmt.Errorf("encoding events: %w", err)
+	}
+
+	if err := writeOutput(*outputPath, &out); err != nil {
+		return fmt.Errorf("writing events: %w", err)
+	}
+
+	fmt.Fprintf(os.Stderr, "Generated %d events for %d competitors\n", len(events), *competitors)
+	return nil
+}
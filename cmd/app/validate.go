@@ -0,0 +1,60 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"biathlon/config"
+	"biathlon/lint"
+)
+
+func runValidate(args []string) error {
+	fs := newFlagSet("validate", "-config config.json -events events (or: config.json events)")
+	configPath := fs.String("config", "", `configuration file ("-" for stdin)`)
+	eventsPath := fs.String("events", "", `events file ("-" for stdin)`)
+	fs.Parse(args)
+
+	if *configPath == "" && *eventsPath == "" && fs.NArg() == 2 {
+		*configPath, *eventsPath = fs.Arg(0), fs.Arg(1)
+	} else if fs.NArg() > 0 {
+		return usageError(fs, "unexpected arguments")
+	}
+	if *configPath == "" || *eventsPath == "" {
+		return usageError(fs, "config and events are required")
+	}
+	if *configPath == "-" && *eventsPath == "-" {
+		return usageError(fs, "config and events cannot both be read from stdin")
+	}
+
+	// Ошибки конфигурации выводятся через lint вместе с остальными, поэтому здесь только разбор
+	configData, err := readInput(*configPath)
+	if err != nil {
+		return withCode(exitConfig, fmt.Errorf("%s: %w", *configPath, err))
+	}
+	cfg, err := config.Parse(configData)
+	if err != nil {
+		return withCode(exitConfig, fmt.Errorf("%s: %w", *configPath, err))
+	}
+
+	data, err := readInput(*eventsPath)
+	if err != nil {
+		return fmt.Errorf("%s: %w", *eventsPath, err)
+	}
+
+	issues := lint.Lint(*configPath, cfg, *eventsPath, data)
+	for _, issue := range issues {
+		fmt.Fprintln(os.Stderr, issue)
+	}
+
+	switch {
+	case lint.HasErrorsOfKind(issues, lint.KindConfig):
+		return withCode(exitConfig, fmt.Errorf("%s: invalid configuration", *configPath))
+	case lint.HasErrorsOfKind(issues, lint.KindParse):
+		return withCode(exitParse, fmt.Errorf("%s: malformed events", *eventsPath))
+	case lint.HasErrors(issues):
+		return withCode(exitRules, fmt.Errorf("%s: rule violations", *eventsPath))
+	}
+
+	fmt.Printf("%s: ok (%d warnings)\n", *eventsPath, len(issues))
+	return nil
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"time"
+
+	"biathlon/watch"
+)
+
+func runWatch(args []string) error {
+	fs := newFlagSet("watch", "[-interval 1s] -config config.json -events events -out output_prefix")
+	configPath := fs.String("config", "", "configuration file")
+	eventsPath := fs.String("events", "", "events file that is appended to during the race")
+	outputPrefix := fs.String("out", "", "prefix of output files (_log.txt and _report.txt)")
+	interval := fs.Duration("interval", time.Second, "how often to check the events file for new lines")
+	fs.Parse(args)
+
+	switch {
+	case fs.NArg() > 0:
+		return usageError(fs, "unexpected arguments")
+	case *configPath == "" || *eventsPath == "" || *outputPrefix == "":
+		return usageError(fs, "config, events and output prefix are required")
+	case *eventsPath == "-" || *outputPrefix == "-":
+		return usageError(fs, "watch needs a real events file and output prefix")
+	case *interval <= 0:
+		return usageError(fs, "interval must be positive")
+	}
+
+	cfg, err := loadConfig(*configPath)
+	if err != nil {
+		return err
+	}
+
+	outputDir := filepath.Dir(*outputPrefix)
+	if outputDir != "" && outputDir != "." {
+		if err := os.MkdirAll(outputDir, 0755); err != nil {
+			return fmt.Errorf("creating output directory: %w", err)
+		}
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	w := &watch.Watcher{
+		Config:       cfg,
+		EventsPath:   *eventsPath,
+		OutputPrefix: *outputPrefix,
+		Interval:     *interval,
+		Logf: func(format string, args ...any) {
+			fmt.Fprintf(os.Stderr, format+"\n", args...)
+		},
+	}
+
+	fmt.Fprintf(os.Stderr, "Watching %s, press Ctrl+C to stop\n", *eventsPath)
+	return w.Run(ctx)
+}
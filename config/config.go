@@ -2,16 +2,207 @@ package config
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"os"
+	"time"
 )
 
 type Config struct {
-	Laps        int    `json:"laps"`
-	LapLen      int    `json:"lapLen"`
-	PenaltyLen  int    `json:"penaltyLen"`
-	FiringLines int    `json:"firingLines"`
-	Start       string `json:"start"`
-	StartDelta  string `json:"startDelta"`
+	Laps        int     `json:"-"` // количество кругов; в JSON "laps" - число или массив описаний кругов
+	LapList     []Lap   `json:"-"`
+	LapLen      int     `json:"lapLen"`
+	PenaltyLen  int     `json:"penaltyLen"`
+	FiringLines int     `json:"firingLines"`
+	Start       string  `json:"start"`
+	StartDelta  string  `json:"startDelta"`
+	Course      *Course `json:"course,omitempty"`
+	// Порядок положений для стрельбы на рубежах, например ["P", "S"]
+	FiringPositions []string `json:"shootingOrder,omitempty"`
+	// Количество стрелковых установок на рубеже; 0 - номер установки не проверяется
+	RangeLanes int `json:"rangeLanes,omitempty"`
+	// Физические границы для поиска аномалий хронометража
+	Limits *Limits `json:"limits,omitempty"`
+	// Формат старта: ModeIndividual (по умолчанию), ModeMassStart или ModePursuit
+	Mode string `json:"mode,omitempty"`
+	// Сведения для официального протокола: название, жюри, погода, данные трассы
+	Info *RaceInfo `json:"info,omitempty"`
+}
+
+const (
+	ModeIndividual = "individual"
+	ModeMassStart  = "massStart"
+	ModePursuit    = "pursuit"
+)
+
+// IsMassStart сообщает, что все участники стартуют одновременно в Start
+func (c *Config) IsMassStart() bool {
+	return c.Mode == ModeMassStart
+}
+
+// IsHeadToHead сообщает, что места в гонке определяются порядком прохождения дистанции: масс-старт или преследование
+func (c *Config) IsHeadToHead() bool {
+	return c.Mode == ModeMassStart || c.Mode == ModePursuit
+}
+
+const (
+	DefaultMinSpeed = 0.1
+	DefaultMaxSpeed = 12.0
+)
+
+type Limits struct {
+	MinSpeed     float64 `json:"minSpeed,omitempty"`     // м/с, по умолчанию DefaultMinSpeed
+	MaxSpeed     float64 `json:"maxSpeed,omitempty"`     // м/с, по умолчанию DefaultMaxSpeed
+	MinRangeTime string  `json:"minRangeTime,omitempty"` // минимальное время на рубеже, HH:MM:SS; пусто - не проверяется
+}
+
+// limits возвращает заданные границы или пустые, если они не заданы
+func (c *Config) limits() Limits {
+	if c.Limits == nil {
+		return Limits{}
+	}
+	return *c.Limits
+}
+
+func (c *Config) SpeedLimits() (float64, float64) {
+	minSpeed, maxSpeed := c.limits().MinSpeed, c.limits().MaxSpeed
+	if minSpeed <= 0 {
+		minSpeed = DefaultMinSpeed
+	}
+	if maxSpeed <= 0 {
+		maxSpeed = DefaultMaxSpeed
+	}
+	return minSpeed, maxSpeed
+}
+
+func (c *Config) MinRangeDuration() time.Duration {
+	if c.limits().MinRangeTime == "" {
+		return 0
+	}
+	return clockDuration(c.limits().MinRangeTime)
+}
+
+const (
+	PositionProne    = "P"
+	PositionStanding = "S"
+)
+
+// Lap описывает отдельный круг дистанции
+type Lap struct {
+	Length   int    `json:"length"`
+	Shooting bool   `json:"shooting,omitempty"` // круг заканчивается огневым рубежом
+	Position string `json:"position,omitempty"` // положение для стрельбы на этом рубеже
+}
+
+func (c *Config) UnmarshalJSON(data []byte) error {
+	type plain Config
+	aux := struct {
+		*plain
+		Laps json.RawMessage `json:"laps"`
+	}{plain: (*plain)(c)}
+
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+
+	c.Laps, c.LapList = 0, nil
+	if len(aux.Laps) == 0 || string(aux.Laps) == "null" {
+		return nil
+	}
+	if aux.Laps[0] == '[' {
+		if err := json.Unmarshal(aux.Laps, &c.LapList); err != nil {
+			return fmt.Errorf("invalid laps: %w", err)
+		}
+		c.Laps = len(c.LapList)
+		return nil
+	}
+	if err := json.Unmarshal(aux.Laps, &c.Laps); err != nil {
+		return fmt.Errorf("invalid laps: %w", err)
+	}
+	return nil
+}
+
+func (c Config) MarshalJSON() ([]byte, error) {
+	type plain Config
+	var laps any = c.Laps
+	if len(c.LapList) > 0 {
+		laps = c.LapList
+	}
+
+	return json.Marshal(struct {
+		plain
+		Laps any `json:"laps"`
+	}{plain: plain(c), Laps: laps})
+}
+
+// LapLength возвращает длину круга с номером lap (с 1)
+func (c *Config) LapLength(lap int) int {
+	if lap >= 1 && lap <= len(c.LapList) {
+		return c.LapList[lap-1].Length
+	}
+	return c.LapLen
+}
+
+// HasShooting сообщает, есть ли на круге огневой рубеж. Без описаний кругов стрельба есть на каждом круге:
+// firingLines - число огневых рубежей на круге, а не число кругов со стрельбой.
+func (c *Config) HasShooting(lap int) bool {
+	if len(c.LapList) > 0 {
+		return lap >= 1 && lap <= len(c.LapList) && c.LapList[lap-1].Shooting
+	}
+	return lap >= 1 && lap <= c.Laps && c.FiringLines > 0
+}
+
+// ShootingOrder возвращает положения для стрельбы по порядку рубежей: из shootingOrder
+// или из описаний кругов. Пустой результат означает, что порядок не задан.
+func (c *Config) ShootingOrder() []string {
+	if len(c.FiringPositions) > 0 {
+		return c.FiringPositions
+	}
+
+	var order []string
+	for _, lap := range c.LapList {
+		if lap.Shooting && lap.Position != "" {
+			order = append(order, lap.Position)
+		}
+	}
+	return order
+}
+
+func (c *Config) shootingLaps() int {
+	count := 0
+	for lap := 1; lap <= c.Laps; lap++ {
+		if c.HasShooting(lap) {
+			count++
+		}
+	}
+	return count
+}
+
+func (c *Config) TotalDistance() int {
+	total := 0
+	for lap := 1; lap <= c.Laps; lap++ {
+		total += c.LapLength(lap)
+	}
+	return total
+}
+
+// Course описывает геометрию круга: расстояния в метрах от начала круга
+type Course struct {
+	RangeEntrance   int `json:"rangeEntrance"`
+	RangeLength     int `json:"rangeLength"`
+	PenaltyEntrance int `json:"penaltyEntrance"`
+	Finish          int `json:"finish,omitempty"` // по умолчанию равно длине круга
+}
+
+func (c *Config) RangeExit() int {
+	return c.Course.RangeEntrance + c.Course.RangeLength
+}
+
+func (c *Config) CourseFinish(lap int) int {
+	if c.Course.Finish > 0 && len(c.LapList) == 0 {
+		return c.Course.Finish
+	}
+	return c.LapLength(lap)
 }
 
 func LoadFromFile(path string) (*Config, error) {
@@ -19,7 +210,10 @@ func LoadFromFile(path string) (*Config, error) {
 	if err != nil {
 		return nil, err
 	}
+	return Parse(data)
+}
 
+func Parse(data []byte) (*Config, error) {
 	var config Config
 	if err := json.Unmarshal(data, &config); err != nil {
 		return nil, err
@@ -27,3 +221,131 @@ func LoadFromFile(path string) (*Config, error) {
 
 	return &config, nil
 }
+
+// Validate возвращает все найденные ошибки конфигурации, а не только первую
+func (c *Config) Validate() []error {
+	var errs []error
+
+	if c.Laps <= 0 {
+		errs = append(errs, fmt.Errorf("laps must be positive, got %d", c.Laps))
+	}
+	if len(c.LapList) == 0 && c.LapLen <= 0 {
+		errs = append(errs, fmt.Errorf("lapLen must be positive, got %d", c.LapLen))
+	}
+	for i, lap := range c.LapList {
+		if lap.Length <= 0 {
+			errs = append(errs, fmt.Errorf("laps[%d].length must be positive, got %d", i, lap.Length))
+		}
+	}
+	if c.PenaltyLen <= 0 {
+		errs = append(errs, fmt.Errorf("penaltyLen must be positive, got %d", c.PenaltyLen))
+	}
+	if c.FiringLines < 0 {
+		errs = append(errs, fmt.Errorf("firingLines must not be negative, got %d", c.FiringLines))
+	}
+	if minSpeed, maxSpeed := c.SpeedLimits(); minSpeed >= maxSpeed {
+		errs = append(errs, fmt.Errorf("limits.minSpeed (%g) must be less than limits.maxSpeed (%g)", minSpeed, maxSpeed))
+	}
+	if minRangeTime := c.limits().MinRangeTime; minRangeTime != "" {
+		if _, err := ParseClock(minRangeTime); err != nil {
+			errs = append(errs, fmt.Errorf("invalid limits.minRangeTime %q: %w", minRangeTime, err))
+		}
+	}
+	if c.RangeLanes < 0 {
+		errs = append(errs, fmt.Errorf("rangeLanes must not be negative, got %d", c.RangeLanes))
+	}
+	if _, err := ParseClock(c.Start); err != nil {
+		errs = append(errs, fmt.Errorf("invalid start %q: %w", c.Start, err))
+	}
+	if c.Mode != "" && c.Mode != ModeIndividual && c.Mode != ModeMassStart && c.Mode != ModePursuit {
+		errs = append(errs, fmt.Errorf("invalid mode %q, expected %s, %s or %s", c.Mode, ModeIndividual, ModeMassStart, ModePursuit))
+	}
+	switch delta, err := c.StartDeltaDuration(); {
+	case c.IsMassStart() && c.StartDelta == "":
+		// В масс-старте интервал нужен только для отметки неявки на старт и может отсутствовать
+	case err != nil:
+		errs = append(errs, fmt.Errorf("invalid startDelta %q: %w", c.StartDelta, err))
+	case delta <= 0:
+		errs = append(errs, fmt.Errorf("startDelta must be positive, got %s", c.StartDelta))
+	}
+
+	if c.Course != nil {
+		errs = append(errs, c.validateCourse()...)
+	}
+	errs = append(errs, c.validateShootingOrder()...)
+	if c.Info != nil {
+		errs = append(errs, c.validateInfo()...)
+	}
+
+	return errs
+}
+
+func (c *Config) validateShootingOrder() []error {
+	var errs []error
+
+	for _, lap := range c.LapList {
+		if lap.Position != "" && lap.Position != PositionProne && lap.Position != PositionStanding {
+			errs = append(errs, fmt.Errorf("invalid lap position %q, expected %s or %s", lap.Position, PositionProne, PositionStanding))
+		}
+	}
+
+	for _, position := range c.FiringPositions {
+		if position != PositionProne && position != PositionStanding {
+			errs = append(errs, fmt.Errorf("invalid shootingOrder position %q, expected %s or %s", position, PositionProne, PositionStanding))
+		}
+	}
+
+	if order := c.ShootingOrder(); len(order) > 0 && len(order) != c.shootingLaps() {
+		errs = append(errs, fmt.Errorf("shootingOrder has %d positions, but the race has %d shooting stages", len(order), c.shootingLaps()))
+	}
+
+	return errs
+}
+
+func (c *Config) validateCourse() []error {
+	var errs []error
+
+	if c.Course.RangeEntrance <= 0 {
+		errs = append(errs, fmt.Errorf("course.rangeEntrance must be positive, got %d", c.Course.RangeEntrance))
+	}
+	if c.Course.RangeLength < 0 {
+		errs = append(errs, fmt.Errorf("course.rangeLength must not be negative, got %d", c.Course.RangeLength))
+	}
+	if c.Course.PenaltyEntrance < c.RangeExit() {
+		errs = append(errs, fmt.Errorf("course.penaltyEntrance (%d) must not be before the range exit (%d)", c.Course.PenaltyEntrance, c.RangeExit()))
+	}
+	for lap := 1; lap <= c.Laps; lap++ {
+		if c.CourseFinish(lap) <= c.Course.PenaltyEntrance {
+			errs = append(errs, fmt.Errorf("course.finish (%d) of lap %d must be after course.penaltyEntrance (%d)", c.CourseFinish(lap), lap, c.Course.PenaltyEntrance))
+			break
+		}
+	}
+
+	return errs
+}
+
+func (c *Config) StartDeltaDuration() (time.Duration, error) {
+	if _, err := ParseClock(c.StartDelta); err != nil {
+		return 0, err
+	}
+	return clockDuration(c.StartDelta), nil
+}
+
+// clockDuration переводит HH:MM:SS[.sss] в длительность, при ошибке возвращает 0
+func clockDuration(s string) time.Duration {
+	t, err := ParseClock(s)
+	if err != nil {
+		return 0
+	}
+	return t.Sub(time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location()))
+}
+
+// ParseClock разбирает время в формате HH:MM:SS или HH:MM:SS.sss
+func ParseClock(s string) (time.Time, error) {
+	for _, layout := range []string{"15:04:05.000", "15:04:05"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, errors.New("expected HH:MM:SS or HH:MM:SS.sss")
+}
@@ -1,8 +1,12 @@
 package config
 
 import (
+	"encoding/json"
 	"os"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestLoadFromFile(t *testing.T) {
@@ -36,3 +40,112 @@ func TestLoadFromFile(t *testing.T) {
 		t.Error("Expected error for nonexistent file")
 	}
 }
+
+func TestValidate(t *testing.T) {
+	cfg := Config{Laps: 2, LapLen: 3500, PenaltyLen: 150, FiringLines: 2, Start: "10:00:00.000", StartDelta: "00:01:30"}
+	if errs := cfg.Validate(); len(errs) != 0 {
+		t.Errorf("Expected valid config, got %v", errs)
+	}
+
+	delta, err := cfg.StartDeltaDuration()
+	if err != nil || delta != 90*time.Second {
+		t.Errorf("StartDeltaDuration() = %v, %v", delta, err)
+	}
+
+	cfg.Course = &Course{RangeEntrance: 2400, RangeLength: 60, PenaltyEntrance: 2520}
+	if errs := cfg.Validate(); len(errs) != 0 || cfg.CourseFinish(1) != 3500 {
+		t.Errorf("Expected valid course, got %v", errs)
+	}
+
+	cfg.Course.PenaltyEntrance = 2000
+	if errs := cfg.Validate(); len(errs) != 1 {
+		t.Errorf("Expected penalty entrance error, got %v", errs)
+	}
+
+	cfg.Course = nil
+	cfg.FiringPositions = []string{PositionProne, "X", PositionStanding}
+	if errs := cfg.Validate(); len(errs) != 2 {
+		t.Errorf("Expected position and stage count errors, got %v", errs)
+	}
+
+	mass := Config{Laps: 2, LapLen: 3000, PenaltyLen: 150, Start: "10:00:00", Mode: ModeMassStart}
+	if errs := mass.Validate(); len(errs) != 0 {
+		t.Errorf("Mass start without startDelta should be valid, got %v", errs)
+	}
+	mass.Mode = "relay"
+	if errs := mass.Validate(); len(errs) != 2 {
+		t.Errorf("Expected mode and startDelta errors, got %v", errs)
+	}
+
+	humidity := 120.0
+	cfg.FiringPositions = nil
+	cfg.Info = &RaceInfo{Date: "19.10.2026", Jury: []JuryMember{{Role: "Race Director"}},
+		Weather: &Weather{Humidity: &humidity}}
+	if errs := cfg.Validate(); len(errs) != 3 {
+		t.Errorf("Expected date, jury and humidity errors, got %v", errs)
+	}
+
+	invalid := Config{Laps: 0, LapLen: -1, PenaltyLen: 150, Start: "10:00", StartDelta: "00:00:00"}
+	if errs := invalid.Validate(); len(errs) != 4 {
+		t.Errorf("Expected 4 errors, got %v", errs)
+	}
+}
+
+func TestHasShooting(t *testing.T) {
+	// firingLines - рубежи на каждом круге, а не число кругов со стрельбой
+	cfg := Config{Laps: 3, LapLen: 3000, FiringLines: 1}
+	for lap := 1; lap <= 3; lap++ {
+		if !cfg.HasShooting(lap) {
+			t.Errorf("Expected shooting on lap %d", lap)
+		}
+	}
+	if cfg.HasShooting(0) || cfg.HasShooting(4) {
+		t.Error("Unexpected shooting outside the race laps")
+	}
+}
+
+func TestLapList(t *testing.T) {
+	const data = `{
+		"laps": [
+			{"length": 3300, "shooting": true, "position": "P"},
+			{"length": 2500, "shooting": true, "position": "S"},
+			{"length": 2000}
+		],
+		"penaltyLen": 150,
+		"start": "10:00:00",
+		"startDelta": "00:00:30"
+	}`
+
+	var cfg Config
+	if err := json.Unmarshal([]byte(data), &cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	if cfg.Laps != 3 || cfg.LapLength(1) != 3300 || cfg.LapLength(3) != 2000 || cfg.TotalDistance() != 7800 {
+		t.Errorf("Lap list parsing mismatch. Got %+v", cfg)
+	}
+	if !cfg.HasShooting(2) || cfg.HasShooting(3) {
+		t.Error("Unexpected shooting laps")
+	}
+	if order := cfg.ShootingOrder(); !reflect.DeepEqual(order, []string{PositionProne, PositionStanding}) {
+		t.Errorf("Unexpected shooting order: %v", order)
+	}
+	if errs := cfg.Validate(); len(errs) != 0 {
+		t.Errorf("Expected valid config, got %v", errs)
+	}
+
+	encoded, err := json.Marshal(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(encoded), "limits") {
+		t.Errorf("Unset limits must be omitted: %s", encoded)
+	}
+	var decoded Config
+	if err := json.Unmarshal(encoded, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(cfg, decoded) {
+		t.Errorf("Round trip mismatch: %+v != %+v", decoded, cfg)
+	}
+}
@@ -0,0 +1,68 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+// RaceInfo - сведения о гонке для официального протокола; на обработку событий не влияют
+type RaceInfo struct {
+	Name        string `json:"name,omitempty"`        // например "Men 10 km Sprint"
+	Competition string `json:"competition,omitempty"` // например "IBU Cup 2026"
+	Venue       string `json:"venue,omitempty"`
+	Date        string `json:"date,omitempty"` // YYYY-MM-DD
+
+	Jury    []JuryMember `json:"jury,omitempty"`
+	Weather *Weather     `json:"weather,omitempty"`
+	Course  *CourseInfo  `json:"course,omitempty"`
+}
+
+type JuryMember struct {
+	Role   string `json:"role"` // например "Technical Delegate"
+	Name   string `json:"name"`
+	Nation string `json:"nation,omitempty"`
+}
+
+// Weather - погода на старте; температуры в градусах Цельсия, влажность в процентах
+type Weather struct {
+	Conditions      string   `json:"conditions,omitempty"`
+	AirTemperature  *float64 `json:"airTemperature,omitempty"`
+	SnowTemperature *float64 `json:"snowTemperature,omitempty"`
+	Humidity        *float64 `json:"humidity,omitempty"`
+	Wind            string   `json:"wind,omitempty"`
+}
+
+// CourseInfo - данные омологации трассы, высоты в метрах
+type CourseInfo struct {
+	Name             string `json:"name,omitempty"`
+	Homologation     string `json:"homologation,omitempty"`
+	HeightDifference int    `json:"heightDifference,omitempty"`
+	MaxClimb         int    `json:"maxClimb,omitempty"`
+	TotalClimb       int    `json:"totalClimb,omitempty"`
+}
+
+const DateFormat = "2006-01-02"
+
+func (c *Config) validateInfo() []error {
+	var errs []error
+	info := c.Info
+
+	if info.Date != "" {
+		if _, err := time.Parse(DateFormat, info.Date); err != nil {
+			errs = append(errs, fmt.Errorf("invalid info.date %q, expected YYYY-MM-DD", info.Date))
+		}
+	}
+	for i, member := range info.Jury {
+		if member.Role == "" || member.Name == "" {
+			errs = append(errs, fmt.Errorf("info.jury[%d] must have role and name", i))
+		}
+	}
+	if w := info.Weather; w != nil && w.Humidity != nil && (*w.Humidity < 0 || *w.Humidity > 100) {
+		errs = append(errs, fmt.Errorf("info.weather.humidity must be between 0 and 100, got %g", *w.Humidity))
+	}
+	if course := info.Course; course != nil && (course.HeightDifference < 0 || course.MaxClimb < 0 || course.TotalClimb < 0) {
+		errs = append(errs, fmt.Errorf("info.course heights must not be negative"))
+	}
+
+	return errs
+}
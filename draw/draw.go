@@ -0,0 +1,154 @@
+package draw
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+	"sort"
+	"strings"
+	"time"
+
+	"biathlon/config"
+	"biathlon/event"
+	"biathlon/model"
+	"biathlon/roster"
+)
+
+type Options struct {
+	Seed int64
+	// Порядок стартовых групп; группы, которых нет в списке, стартуют следом по алфавиту,
+	// спортсмены без группы - последними
+	Groups []string
+}
+
+// Entry - строка стартового протокола
+type Entry struct {
+	Position     int    `json:"position"`
+	CompetitorID int    `json:"competitorId"`
+	Name         string `json:"name,omitempty"`
+	Group        string `json:"group,omitempty"`
+	StartTime    string `json:"startTime"`
+}
+
+// Draw проводит жеребьёвку: внутри каждой группы порядок случайный, группы стартуют блоками друг за другом
+// с интервалом StartDelta начиная с Start. Результат детерминирован для заданного Seed.
+func Draw(cfg *config.Config, r roster.Roster, opts Options) ([]Entry, error) {
+	if errs := cfg.Validate(); len(errs) > 0 {
+		return nil, fmt.Errorf("invalid config: %w", errors.Join(errs...))
+	}
+	if len(r) == 0 {
+		return nil, fmt.Errorf("roster is empty")
+	}
+
+	start, _ := config.ParseClock(cfg.Start)
+	startDelta, _ := cfg.StartDeltaDuration()
+
+	groups := make(map[string][]int)
+	for _, id := range r.IDs() {
+		group := r[id].Group
+		groups[group] = append(groups[group], id)
+	}
+
+	rng := rand.New(rand.NewSource(opts.Seed))
+	entries := make([]Entry, 0, len(r))
+	for _, group := range groupOrder(groups, opts.Groups) {
+		ids := groups[group]
+		rng.Shuffle(len(ids), func(i, j int) {
+			ids[i], ids[j] = ids[j], ids[i]
+		})
+
+		for _, id := range ids {
+			entries = append(entries, Entry{
+				Position:     len(entries) + 1,
+				CompetitorID: id,
+				Name:         r[id].Name,
+				Group:        group,
+				StartTime:    start.Add(time.Duration(len(entries)) * startDelta).Format(model.TimeFormat),
+			})
+		}
+	}
+
+	return entries, nil
+}
+
+func groupOrder(groups map[string][]int, order []string) []string {
+	listed := make(map[string]bool)
+	result := make([]string, 0, len(groups))
+	for _, group := range order {
+		if _, ok := groups[group]; ok && !listed[group] {
+			result = append(result, group)
+		}
+		listed[group] = true
+	}
+
+	var rest []string
+	for group := range groups {
+		if !listed[group] && group != "" {
+			rest = append(rest, group)
+		}
+	}
+	sort.Strings(rest)
+	result = append(result, rest...)
+
+	if _, ok := groups[""]; ok && !listed[""] {
+		result = append(result, "")
+	}
+	return result
+}
+
+// Events возвращает входящие события жеребьёвки: регистрацию и назначение времени старта каждого участника.
+// Все события получают время at, по умолчанию - за полчаса до старта.
+func Events(cfg *config.Config, entries []Entry, at string) ([]event.Event, error) {
+	start, err := config.ParseClock(cfg.Start)
+	if err != nil {
+		return nil, fmt.Errorf("invalid start: %w", err)
+	}
+
+	t := start.Add(-30 * time.Minute)
+	if at != "" {
+		if t, err = config.ParseClock(at); err != nil {
+			return nil, fmt.Errorf("invalid draw time %q: %w", at, err)
+		}
+		if t.After(start) {
+			return nil, fmt.Errorf("draw time %s is after the start %s", at, cfg.Start)
+		}
+	}
+	drawTime := t.Format(model.TimeFormat)
+
+	events := make([]event.Event, 0, 2*len(entries))
+	for _, entry := range entries {
+		events = append(events, event.Event{Time: drawTime, EventID: event.Registered, CompetitorID: entry.CompetitorID})
+	}
+	for _, entry := range entries {
+		events = append(events, event.Event{Time: drawTime, EventID: event.StartTimeSet, CompetitorID: entry.CompetitorID, ExtraParams: entry.StartTime})
+	}
+	return events, nil
+}
+
+// GenerateStartList выводит стартовый протокол: позиция, время старта, номер, имя и группа
+func GenerateStartList(entries []Entry) string {
+	var b strings.Builder
+
+	for _, entry := range entries {
+		name := entry.Name
+		if name == "" {
+			name = fmt.Sprintf("competitor(%d)", entry.CompetitorID)
+		}
+		fmt.Fprintf(&b, "%d. [%s] %d %s", entry.Position, entry.StartTime, entry.CompetitorID, name)
+		if entry.Group != "" {
+			fmt.Fprintf(&b, " (%s)", entry.Group)
+		}
+		b.WriteString("\n")
+	}
+
+	return b.String()
+}
+
+func GenerateStartListJSON(entries []Entry) (string, error) {
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(data) + "\n", nil
+}
@@ -0,0 +1,99 @@
+package draw
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"biathlon/config"
+	"biathlon/event"
+	"biathlon/lint"
+	"biathlon/roster"
+)
+
+var testConfig = &config.Config{
+	Laps:        2,
+	LapLen:      3000,
+	PenaltyLen:  150,
+	FiringLines: 1,
+	Start:       "10:00:00.000",
+	StartDelta:  "00:00:30",
+}
+
+func testRoster() roster.Roster {
+	r := make(roster.Roster)
+	for id := 1; id <= 20; id++ {
+		a := roster.Athlete{ID: id}
+		switch {
+		case id <= 5:
+			a.Group = "red"
+		case id <= 10:
+			a.Group = "blue"
+		case id <= 15:
+			a.Group = "green"
+		}
+		r[id] = a
+	}
+	return r
+}
+
+func TestDraw(t *testing.T) {
+	opts := Options{Seed: 7, Groups: []string{"red", "blue"}}
+
+	entries, err := Draw(testConfig, testRoster(), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	again, _ := Draw(testConfig, testRoster(), opts)
+	if !reflect.DeepEqual(entries, again) {
+		t.Error("Same seed produced different draws")
+	}
+
+	// Группы идут блоками: red, blue, затем неуказанная green и спортсмены без группы
+	var groups []string
+	for i, entry := range entries {
+		if i == 0 || entries[i-1].Group != entry.Group {
+			groups = append(groups, entry.Group)
+		}
+	}
+	if strings.Join(groups, ",") != "red,blue,green," {
+		t.Errorf("Unexpected group order: %q", groups)
+	}
+
+	if entries[0].StartTime != "10:00:00.000" || entries[19].StartTime != "10:09:30.000" {
+		t.Errorf("Unexpected start times: %s, %s", entries[0].StartTime, entries[19].StartTime)
+	}
+
+	opts.Seed++
+	other, _ := Draw(testConfig, testRoster(), opts)
+	if reflect.DeepEqual(entries, other) {
+		t.Error("Different seeds produced identical draws")
+	}
+}
+
+func TestEventsPassValidation(t *testing.T) {
+	entries, err := Draw(testConfig, testRoster(), Options{Seed: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	events, err := Events(testConfig, entries, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 40 || events[0].Time != "09:30:00.000" || events[0].EventID != event.Registered {
+		t.Errorf("Unexpected events: %v", events[:1])
+	}
+
+	var buf []byte
+	for _, evt := range events {
+		buf = append(buf, evt.String()+"\n"...)
+	}
+	if issues := lint.Lint("config.json", testConfig, "events", buf); lint.HasErrors(issues) {
+		t.Errorf("Draw events have problems: %v", issues)
+	}
+
+	if _, err := Events(testConfig, entries, "10:00:01"); err == nil {
+		t.Error("Expected error for draw time after the start")
+	}
+}
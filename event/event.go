@@ -1,8 +1,8 @@
 package event
 
 import (
+	"encoding/json"
 	"fmt"
-	"os"
 	"strconv"
 	"strings"
 	"time"
@@ -22,21 +22,197 @@ const (
 	LeftPenalty     = 9
 	EndedLap        = 10
 	CannotContinue  = 11
+	JuryDecision    = 12
+	PhotoFinish     = 13
 	Disqualified    = 32
 	Finished        = 33
+	Anomaly         = 34
+	Overtake        = 35
+	OvertakeVoided  = 36
 )
 
 type Event struct {
-	Time         string
-	EventID      int
-	CompetitorID int
-	ExtraParams  string
+	Time         string `json:"time"`
+	EventID      int    `json:"eventId"`
+	CompetitorID int    `json:"competitorId"`
+	ExtraParams  string `json:"params,omitempty"`
+}
+
+type StartTimePayload struct {
+	StartTime string `json:"startTime"`
+}
+
+type FiringRangePayload struct {
+	FiringRange int `json:"firingRange"`
+}
+
+type TargetPayload struct {
+	Target int `json:"target"`
+}
+
+type CommentPayload struct {
+	Comment string `json:"comment"`
+}
+
+// Решения жюри: параметры события 12 - "DSQ reason [note]", "REINSTATE [note]"
+// или "PENALTY +HH:MM:SS[.sss] reason [note]"
+const (
+	JuryDisqualify = "DSQ"
+	JuryReinstate  = "REINSTATE"
+	JuryPenalty    = "PENALTY"
+)
+
+type JuryPayload struct {
+	Action  string        `json:"action"`
+	Reason  string        `json:"reason,omitempty"`
+	Penalty time.Duration `json:"penalty,omitempty"`
+	Note    string        `json:"note,omitempty"`
+}
+
+// MarshalJSON записывает штраф в том же виде, что и остальные длительности лога: +HH:MM:SS.sss
+func (p JuryPayload) MarshalJSON() ([]byte, error) {
+	type plain JuryPayload
+	penalty := ""
+	if p.Penalty != 0 {
+		penalty = model.FormatSignedDuration(p.Penalty)
+	}
+
+	return json.Marshal(struct {
+		plain
+		Penalty string `json:"penalty,omitempty"`
+	}{plain: plain(p), Penalty: penalty})
+}
+
+func (p *JuryPayload) UnmarshalJSON(data []byte) error {
+	type plain JuryPayload
+	var aux struct {
+		plain
+		Penalty string `json:"penalty,omitempty"`
+	}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+
+	*p = JuryPayload(aux.plain)
+	if aux.Penalty != "" {
+		penalty, err := ParseSignedDuration(aux.Penalty)
+		if err != nil {
+			return err
+		}
+		p.Penalty = penalty
+	}
+	return nil
+}
+
+// PhotoFinishPayload - участник, которого фотофиниш поставил позади участника события
+type PhotoFinishPayload struct {
+	Behind int `json:"behind"`
+}
+
+type OvertakePayload struct {
+	Overtaken int `json:"overtaken"`
+}
+
+type RawPayload struct {
+	Params string `json:"params"`
 }
 
 func (e Event) ParsedTime() (time.Time, error) {
 	return model.ParseTime(e.Time)
 }
 
+// Payload возвращает типизированные дополнительные параметры события
+func (e Event) Payload() any {
+	switch e.EventID {
+	case StartTimeSet:
+		return StartTimePayload{StartTime: e.ExtraParams}
+	case OnFiringRange:
+		firingRange, _ := strconv.Atoi(e.ExtraParams)
+		return FiringRangePayload{FiringRange: firingRange}
+	case TargetHit:
+		target, _ := strconv.Atoi(e.ExtraParams)
+		return TargetPayload{Target: target}
+	case CannotContinue, Anomaly:
+		return CommentPayload{Comment: e.ExtraParams}
+	case JuryDecision:
+		if decision, err := ParseJuryDecision(e.ExtraParams); err == nil {
+			return decision
+		}
+	case PhotoFinish:
+		if behind, err := strconv.Atoi(e.ExtraParams); err == nil {
+			return PhotoFinishPayload{Behind: behind}
+		}
+	case Overtake, OvertakeVoided:
+		if overtaken, err := strconv.Atoi(e.ExtraParams); err == nil {
+			return OvertakePayload{Overtaken: overtaken}
+		}
+	}
+
+	if e.ExtraParams != "" {
+		return RawPayload{Params: e.ExtraParams}
+	}
+	return nil
+}
+
+func IsIncoming(eventID int) bool {
+	return eventID >= Registered && eventID <= PhotoFinish
+}
+
+func ParseJuryDecision(params string) (JuryPayload, error) {
+	parts := strings.Fields(params)
+	if len(parts) == 0 {
+		return JuryPayload{}, fmt.Errorf("empty jury decision")
+	}
+
+	decision := JuryPayload{Action: strings.ToUpper(parts[0])}
+	rest := parts[1:]
+
+	switch decision.Action {
+	case JuryDisqualify:
+		if len(rest) == 0 {
+			return JuryPayload{}, fmt.Errorf("jury disqualification requires a reason code")
+		}
+		decision.Reason, rest = rest[0], rest[1:]
+	case JuryReinstate:
+	case JuryPenalty:
+		if len(rest) < 2 {
+			return JuryPayload{}, fmt.Errorf("jury time penalty requires a signed duration and a reason code")
+		}
+		penalty, err := ParseSignedDuration(rest[0])
+		if err != nil {
+			return JuryPayload{}, err
+		}
+		decision.Penalty, decision.Reason, rest = penalty, rest[1], rest[2:]
+	default:
+		return JuryPayload{}, fmt.Errorf("unknown jury decision %q, expected %s, %s or %s", parts[0], JuryDisqualify, JuryReinstate, JuryPenalty)
+	}
+
+	decision.Note = strings.Join(rest, " ")
+	return decision, nil
+}
+
+// ParseSignedDuration разбирает длительность вида +HH:MM:SS[.sss] или -HH:MM:SS[.sss]
+func ParseSignedDuration(s string) (time.Duration, error) {
+	if len(s) < 2 || (s[0] != '+' && s[0] != '-') {
+		return 0, fmt.Errorf("invalid signed duration %q, expected +HH:MM:SS or -HH:MM:SS", s)
+	}
+
+	value := s[1:]
+	if len(value) == len("15:04:05") {
+		value += ".000"
+	}
+	if !isTimeValid(value) {
+		return 0, fmt.Errorf("invalid signed duration %q, expected +HH:MM:SS or -HH:MM:SS", s)
+	}
+
+	t, _ := model.ParseTime(value)
+	d := t.Sub(time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location()))
+	if s[0] == '-' {
+		d = -d
+	}
+	return d, nil
+}
+
 func Parse(line string) (Event, error) {
 	timeStart := strings.Index(line, "[")
 	timeEnd := strings.Index(line, "]")
@@ -44,63 +220,55 @@ func Parse(line string) (Event, error) {
 		return Event{}, fmt.Errorf("invalid event format: %s", line)
 	}
 
-	timeStr := line[timeStart+1 : timeEnd]
-	if !isTimeValid(timeStr) {
-		return Event{}, fmt.Errorf("invalid event time format: %s", timeStr)
+	// Параметры - весь остаток строки после номера участника: пробелы внутри комментариев сохраняются
+	eventID, rest := nextField(line[timeEnd+1:])
+	competitorID, rest := nextField(rest)
+	if competitorID == "" {
+		return Event{}, fmt.Errorf("not enough event parts: %s", line)
 	}
 
-	parts := strings.Fields(line[timeEnd+1:])
-	if len(parts) < 2 {
-		return Event{}, fmt.Errorf("not enough event parts: %s", line)
+	return newEvent(line[timeStart+1:timeEnd], eventID, competitorID, rest)
+}
+
+func nextField(s string) (field, rest string) {
+	s = strings.TrimLeft(s, " \t")
+	if i := strings.IndexAny(s, " \t"); i >= 0 {
+		return s[:i], s[i:]
 	}
+	return s, ""
+}
 
-	eventID, err := strconv.Atoi(parts[0])
-	if err != nil {
-		return Event{}, fmt.Errorf("invalid event ID: %s", parts[0])
+func newEvent(timeStr, eventIDStr, competitorIDStr, extraParams string) (Event, error) {
+	if !isTimeValid(timeStr) {
+		return Event{}, fmt.Errorf("invalid event time format: %s", timeStr)
 	}
 
-	competitorID, err := strconv.Atoi(parts[1])
+	eventID, err := strconv.Atoi(eventIDStr)
 	if err != nil {
-		return Event{}, fmt.Errorf("invalid competitor ID: %s", parts[1])
+		return Event{}, fmt.Errorf("invalid event ID: %s", eventIDStr)
 	}
 
-	extraParams := ""
-	if len(parts) > 2 {
-		extraParams = strings.Join(parts[2:], " ")
+	competitorID, err := strconv.Atoi(competitorIDStr)
+	if err != nil {
+		return Event{}, fmt.Errorf("invalid competitor ID: %s", competitorIDStr)
 	}
 
+	// Пробелы по краям параметров во всех форматах отбрасываются, иначе текстовый формат их не сохранит
 	return Event{
 		Time:         timeStr,
 		EventID:      eventID,
 		CompetitorID: competitorID,
-		ExtraParams:  extraParams,
+		ExtraParams:  strings.TrimSpace(extraParams),
 	}, nil
 }
 
-func LoadFromFile(path string) ([]Event, error) {
-	data, err := os.ReadFile(path)
-	if err != nil {
-		return nil, err
-	}
-
-	lines := strings.Split(string(data), "\n")
-	events := make([]Event, 0, len(lines))
-
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if line == "" {
-			continue
-		}
-
-		event, err := Parse(line)
-		if err != nil {
-			return nil, err
-		}
-
-		events = append(events, event)
+// String возвращает событие в исходном формате [HH:MM:SS.sss] id competitor params
+func (e Event) String() string {
+	line := fmt.Sprintf("[%s] %d %d", e.Time, e.EventID, e.CompetitorID)
+	if e.ExtraParams != "" {
+		line += " " + e.ExtraParams
 	}
-
-	return events, nil
+	return line
 }
 
 func FormatLogEntry(event Event) string {
@@ -129,10 +297,20 @@ func FormatLogEntry(event Event) string {
 		return fmt.Sprintf("The competitor(%d) ended the main lap", event.CompetitorID)
 	case CannotContinue:
 		return fmt.Sprintf("The competitor(%d) can`t continue: %s", event.CompetitorID, event.ExtraParams)
+	case JuryDecision:
+		return fmt.Sprintf("The jury decision for competitor(%d): %s", event.CompetitorID, event.ExtraParams)
+	case PhotoFinish:
+		return fmt.Sprintf("The photo finish placed competitor(%d) ahead of competitor(%s)", event.CompetitorID, event.ExtraParams)
 	case Disqualified:
 		return fmt.Sprintf("The competitor(%d) is disqualified", event.CompetitorID)
 	case Finished:
 		return fmt.Sprintf("The competitor(%d) has finished", event.CompetitorID)
+	case Anomaly:
+		return fmt.Sprintf("Warning for competitor(%d): %s", event.CompetitorID, event.ExtraParams)
+	case Overtake:
+		return fmt.Sprintf("The competitor(%d) overtook competitor(%s)", event.CompetitorID, event.ExtraParams)
+	case OvertakeVoided:
+		return fmt.Sprintf("The photo finish voided the overtake of competitor(%s) by competitor(%d)", event.ExtraParams, event.CompetitorID)
 	default:
 		return fmt.Sprintf("Unknown event: %d for competitor(%d) with params: %s", event.EventID, event.CompetitorID, event.ExtraParams)
 	}
@@ -1,7 +1,12 @@
 package event
 
 import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestParseEvent(t *testing.T) {
@@ -45,3 +50,161 @@ func TestParsedTime(t *testing.T) {
 		t.Errorf("Parsed time mismatch: %v", pt)
 	}
 }
+
+func TestPayload(t *testing.T) {
+	tests := []struct {
+		evt  Event
+		want any
+	}{
+		{Event{EventID: StartTimeSet, ExtraParams: "10:00:00.000"}, StartTimePayload{StartTime: "10:00:00.000"}},
+		{Event{EventID: OnFiringRange, ExtraParams: "2"}, FiringRangePayload{FiringRange: 2}},
+		{Event{EventID: TargetHit, ExtraParams: "5"}, TargetPayload{Target: 5}},
+		{Event{EventID: CannotContinue, ExtraParams: "Lost in the forest"}, CommentPayload{Comment: "Lost in the forest"}},
+		{Event{EventID: Registered}, nil},
+	}
+
+	for _, tt := range tests {
+		if got := tt.evt.Payload(); got != tt.want {
+			t.Errorf("Payload(%v) = %v, want %v", tt.evt, got, tt.want)
+		}
+	}
+}
+
+func TestFormatsRoundTrip(t *testing.T) {
+	events := []Event{
+		{"09:05:59.867", Registered, 1, ""},
+		{"09:15:00.841", StartTimeSet, 1, "09:30:00.000"},
+		{"09:59:03.872", CannotContinue, 1, "Lost in the forest, again"},
+		{"10:30:00.000", JuryDecision, 1, "DSQ IllegalEquipment Wax  test:  \"failed\""},
+	}
+
+	for _, name := range FormatNames() {
+		format, err := LookupFormat(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var buf bytes.Buffer
+		if err := format.Encode(&buf, events); err != nil {
+			t.Fatalf("%s: encode error: %v", name, err)
+		}
+
+		if detected := DetectFormat("events", buf.Bytes()); detected.Name() != name {
+			t.Errorf("%s: detected as %s", name, detected.Name())
+		}
+
+		got, err := format.Decode(&buf)
+		if err != nil {
+			t.Fatalf("%s: decode error: %v", name, err)
+		}
+		if !reflect.DeepEqual(got, events) {
+			t.Errorf("%s: round trip = %v, want %v", name, got, events)
+		}
+	}
+}
+
+func TestConvertChain(t *testing.T) {
+	input := "[10:30:00.000] 12  1   DSQ IllegalEquipment   two  spaces, comma  \n"
+	want := "[10:30:00.000] 12 1 DSQ IllegalEquipment   two  spaces, comma\n"
+
+	events, err := TextFormat{}.Decode(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"csv", "jsonl", "text", "jsonl", "csv", "text"} {
+		format, _ := LookupFormat(name)
+		var buf bytes.Buffer
+		if err := format.Encode(&buf, events); err != nil {
+			t.Fatalf("%s: encode error: %v", name, err)
+		}
+		if events, err = format.Decode(&buf); err != nil {
+			t.Fatalf("%s: decode error: %v", name, err)
+		}
+	}
+
+	var buf bytes.Buffer
+	TextFormat{}.Encode(&buf, events)
+	if buf.String() != want {
+		t.Errorf("Converted event = %q, want %q", buf.String(), want)
+	}
+}
+
+func TestCSVErrorLine(t *testing.T) {
+	data := "time,eventId,competitorId,params\n09:00:00.000,1,1,\"multi\nline\"\n09:01:00.000,1\n"
+	_, err := CSVFormat{}.Decode(strings.NewReader(data))
+	if err == nil || !strings.Contains(err.Error(), "line 4") {
+		t.Errorf("Expected error on line 4, got %v", err)
+	}
+}
+
+func TestDecodeRecordsMultilineCSV(t *testing.T) {
+	data := "time,eventId,competitorId,params\n" +
+		"10:30:00.000,12,1,\"DSQ IllegalEquipment Ski wax\ntest failed\"\n" +
+		"10:31:00.000,bogus,1,\n" +
+		"10:32:00.000,12,2,REINSTATE\n"
+
+	records, errs := DecodeRecords(CSVFormat{}, []byte(data))
+	if len(records) != 2 || records[0].Line != 2 || records[1].Line != 5 {
+		t.Fatalf("Unexpected records: %+v", records)
+	}
+	if records[0].Event.ExtraParams != "DSQ IllegalEquipment Ski wax\ntest failed" {
+		t.Errorf("Multi-line params = %q", records[0].Event.ExtraParams)
+	}
+	if len(errs) != 1 || errs[0].Line != 4 {
+		t.Errorf("Expected one error on line 4, got %v", errs)
+	}
+}
+
+func TestDetectFormatByExtension(t *testing.T) {
+	tests := map[string]string{
+		"events.csv":   "csv",
+		"events.jsonl": "jsonl",
+		"events.txt":   "text",
+	}
+
+	for path, want := range tests {
+		if got := DetectFormat(path, nil).Name(); got != want {
+			t.Errorf("DetectFormat(%q) = %s, want %s", path, got, want)
+		}
+	}
+}
+
+func TestParseJuryDecision(t *testing.T) {
+	tests := []struct {
+		params string
+		want   JuryPayload
+	}{
+		{"DSQ IllegalEquipment Ski wax test failed", JuryPayload{Action: JuryDisqualify, Reason: "IllegalEquipment", Note: "Ski wax test failed"}},
+		{"REINSTATE", JuryPayload{Action: JuryReinstate}},
+		{"PENALTY +00:01:00 WrongLane", JuryPayload{Action: JuryPenalty, Penalty: time.Minute, Reason: "WrongLane"}},
+		{"PENALTY -00:00:10.500 Timing Clock drift", JuryPayload{Action: JuryPenalty, Penalty: -10500 * time.Millisecond, Reason: "Timing", Note: "Clock drift"}},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseJuryDecision(tt.params)
+		if err != nil {
+			t.Errorf("ParseJuryDecision(%q): %v", tt.params, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseJuryDecision(%q) = %+v, want %+v", tt.params, got, tt.want)
+		}
+
+		var decoded JuryPayload
+		data, _ := json.Marshal(got)
+		if err := json.Unmarshal(data, &decoded); err != nil || decoded != tt.want {
+			t.Errorf("JSON round trip of %s = %+v, %v", data, decoded, err)
+		}
+	}
+
+	data, _ := json.Marshal(JuryPayload{Action: JuryPenalty, Penalty: -10500 * time.Millisecond, Reason: "Timing"})
+	if want := `{"action":"PENALTY","reason":"Timing","penalty":"-00:00:10.500"}`; string(data) != want {
+		t.Errorf("Marshal() = %s, want %s", data, want)
+	}
+
+	for _, params := range []string{"", "DSQ", "PENALTY 00:01:00", "PENALTY +1m Reason", "WARN Reason"} {
+		if _, err := ParseJuryDecision(params); err == nil {
+			t.Errorf("ParseJuryDecision(%q): expected error", params)
+		}
+	}
+}
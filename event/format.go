@@ -0,0 +1,321 @@
+package event
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type Decoder interface {
+	Decode(r io.Reader) ([]Event, error)
+}
+
+type Encoder interface {
+	Encode(w io.Writer, events []Event) error
+}
+
+type Format interface {
+	Decoder
+	Encoder
+	Name() string
+	Extensions() []string
+}
+
+var formats = map[string]Format{}
+
+func init() {
+	RegisterFormat(TextFormat{})
+	RegisterFormat(CSVFormat{})
+	RegisterFormat(JSONLinesFormat{})
+}
+
+func RegisterFormat(f Format) {
+	formats[f.Name()] = f
+}
+
+func LookupFormat(name string) (Format, error) {
+	f, ok := formats[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown event format: %s (available: %s)", name, strings.Join(FormatNames(), ", "))
+	}
+	return f, nil
+}
+
+func FormatNames() []string {
+	names := make([]string, 0, len(formats))
+	for name := range formats {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// DetectFormat определяет формат сначала по расширению файла, затем по содержимому
+func DetectFormat(path string, data []byte) Format {
+	ext := strings.ToLower(filepath.Ext(path))
+	if ext != "" {
+		for _, name := range FormatNames() {
+			for _, e := range formats[name].Extensions() {
+				if e == ext {
+					return formats[name]
+				}
+			}
+		}
+	}
+
+	content := strings.TrimSpace(string(data))
+	switch {
+	case strings.HasPrefix(content, "{"):
+		return formats["jsonl"]
+	case content != "" && !strings.HasPrefix(content, "["):
+		return formats["csv"]
+	default:
+		return formats["text"]
+	}
+}
+
+func Decode(path string, data []byte) ([]Event, error) {
+	return DetectFormat(path, data).Decode(strings.NewReader(string(data)))
+}
+
+type TextFormat struct{}
+
+func (TextFormat) Name() string { return "text" }
+
+func (TextFormat) Extensions() []string { return []string{".txt", ".log"} }
+
+func (TextFormat) Decode(r io.Reader) ([]Event, error) {
+	var events []Event
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		event, err := Parse(line)
+		if err != nil {
+			return nil, err
+		}
+
+		events = append(events, event)
+	}
+	return events, scanner.Err()
+}
+
+func (TextFormat) Encode(w io.Writer, events []Event) error {
+	for _, event := range events {
+		if _, err := fmt.Fprintln(w, event.String()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+var csvHeader = []string{"time", "eventId", "competitorId", "params"}
+
+type CSVFormat struct{}
+
+func (CSVFormat) Name() string { return "csv" }
+
+func (CSVFormat) Extensions() []string { return []string{".csv"} }
+
+func (CSVFormat) Decode(r io.Reader) ([]Event, error) {
+	reader := newCSVReader(r)
+
+	var events []Event
+	for first := true; ; first = false {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		line, _ := reader.FieldPos(0)
+
+		event, ok, err := csvEvent(record, first, line)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			events = append(events, event)
+		}
+	}
+	return events, nil
+}
+
+// DecodeRecords разбирает CSV по записям, а не по строкам: поле в кавычках может занимать несколько строк
+func (CSVFormat) DecodeRecords(data []byte) ([]Record, []*LineError) {
+	reader := newCSVReader(bytes.NewReader(data))
+
+	var records []Record
+	var errs []*LineError
+	for first := true; ; first = false {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			line := 0
+			var parseErr *csv.ParseError
+			if errors.As(err, &parseErr) {
+				line = parseErr.StartLine
+			}
+			errs = append(errs, &LineError{Line: line, Err: err})
+			continue
+		}
+		line, _ := reader.FieldPos(0)
+
+		event, ok, err := csvEvent(record, first, line)
+		switch {
+		case err != nil:
+			errs = append(errs, &LineError{Line: line, Err: err})
+		case ok:
+			records = append(records, Record{Line: line, Event: event})
+		}
+	}
+	return records, errs
+}
+
+func newCSVReader(r io.Reader) *csv.Reader {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	return reader
+}
+
+// csvEvent разбирает запись CSV; заголовок в первой записи и пустые строки пропускаются (ok = false)
+func csvEvent(record []string, first bool, line int) (Event, bool, error) {
+	if first && strings.EqualFold(strings.TrimSpace(record[0]), csvHeader[0]) {
+		return Event{}, false, nil
+	}
+	if len(record) == 1 && strings.TrimSpace(record[0]) == "" {
+		return Event{}, false, nil
+	}
+	if len(record) < 3 || len(record) > 4 {
+		return Event{}, false, fmt.Errorf("invalid csv record on line %d: %s", line, strings.Join(record, ","))
+	}
+
+	fields := append(record, "")
+	event, err := newEvent(strings.TrimSpace(fields[0]), strings.TrimSpace(fields[1]), strings.TrimSpace(fields[2]), fields[3])
+	if err != nil {
+		return Event{}, false, err
+	}
+	return event, true, nil
+}
+
+func (CSVFormat) Encode(w io.Writer, events []Event) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(csvHeader); err != nil {
+		return err
+	}
+	for _, event := range events {
+		record := []string{event.Time, strconv.Itoa(event.EventID), strconv.Itoa(event.CompetitorID), event.ExtraParams}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+type JSONLinesFormat struct{}
+
+func (JSONLinesFormat) Name() string { return "jsonl" }
+
+func (JSONLinesFormat) Extensions() []string { return []string{".jsonl", ".ndjson", ".json"} }
+
+func (JSONLinesFormat) Decode(r io.Reader) ([]Event, error) {
+	var events []Event
+	decoder := json.NewDecoder(r)
+	for {
+		var event Event
+		err := decoder.Decode(&event)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid json event: %w", err)
+		}
+		if !isTimeValid(event.Time) {
+			return nil, fmt.Errorf("invalid event time format: %s", event.Time)
+		}
+		event.ExtraParams = strings.TrimSpace(event.ExtraParams)
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+func (JSONLinesFormat) Encode(w io.Writer, events []Event) error {
+	encoder := json.NewEncoder(w)
+	for _, event := range events {
+		if err := encoder.Encode(event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func LoadFromFile(path string) ([]Event, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return Decode(path, data)
+}
+
+type Record struct {
+	Line  int
+	Event Event
+}
+
+type LineError struct {
+	Line int
+	Err  error
+}
+
+func (e *LineError) Error() string {
+	return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+}
+
+// RecordDecoder реализуют форматы, в которых одна запись может занимать несколько строк
+type RecordDecoder interface {
+	DecodeRecords(data []byte) ([]Record, []*LineError)
+}
+
+// DecodeRecords разбирает файл построчно и не останавливается на первой ошибке
+func DecodeRecords(f Format, data []byte) ([]Record, []*LineError) {
+	if decoder, ok := f.(RecordDecoder); ok {
+		return decoder.DecodeRecords(data)
+	}
+
+	var records []Record
+	var errs []*LineError
+
+	for i, line := range strings.Split(string(data), "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		events, err := f.Decode(strings.NewReader(line))
+		if err != nil {
+			errs = append(errs, &LineError{Line: i + 1, Err: err})
+			continue
+		}
+
+		for _, event := range events {
+			records = append(records, Record{Line: i + 1, Event: event})
+		}
+	}
+
+	return records, errs
+}
@@ -0,0 +1,105 @@
+package lint
+
+import (
+	"fmt"
+	"sort"
+
+	"biathlon/config"
+	"biathlon/event"
+	"biathlon/race"
+)
+
+// Категории проблем, по которым CLI выбирает код завершения
+const (
+	KindConfig = "config"
+	KindParse  = "parse"
+	KindRule   = "rule"
+)
+
+type Issue struct {
+	File     string
+	Line     int
+	Kind     string
+	Severity race.Severity
+	Message  string
+}
+
+func (i Issue) String() string {
+	if i.Line == 0 {
+		return fmt.Sprintf("%s: %s: %s", i.File, i.Severity, i.Message)
+	}
+	return fmt.Sprintf("%s:%d: %s: %s", i.File, i.Line, i.Severity, i.Message)
+}
+
+func HasErrors(issues []Issue) bool {
+	return HasErrorsOfKind(issues, "")
+}
+
+// HasErrorsOfKind сообщает, есть ли ошибки указанной категории; пустая категория означает любую
+func HasErrorsOfKind(issues []Issue, kind string) bool {
+	for _, issue := range issues {
+		if issue.Severity == race.SeverityError && (kind == "" || issue.Kind == kind) {
+			return true
+		}
+	}
+	return false
+}
+
+// Lint проверяет конфигурацию и файл событий и возвращает все найденные проблемы.
+// Если конфигурация корректна, события прогоняются через race.Controller без записи результатов.
+func Lint(configPath string, cfg *config.Config, eventsPath string, data []byte) []Issue {
+	var configIssues, issues []Issue
+
+	for _, err := range cfg.Validate() {
+		configIssues = append(configIssues, Issue{File: configPath, Kind: KindConfig, Severity: race.SeverityError, Message: err.Error()})
+	}
+
+	records, parseErrs := event.DecodeRecords(event.DetectFormat(eventsPath, data), data)
+	for _, err := range parseErrs {
+		issues = append(issues, Issue{File: eventsPath, Line: err.Line, Kind: KindParse, Severity: race.SeverityError, Message: err.Err.Error()})
+	}
+
+	// С некорректной конфигурацией контроллер не запускаем
+	if len(configIssues) > 0 {
+		return append(configIssues, issues...)
+	}
+
+	ctrl := race.NewController(cfg)
+	lastLine := make(map[int]int)
+
+	for _, record := range records {
+		lastLine[record.Event.CompetitorID] = record.Line
+
+		for _, problem := range ctrl.CheckOrder(record.Event) {
+			issues = append(issues, Issue{File: eventsPath, Line: record.Line, Kind: KindRule, Severity: problem.Severity, Message: problem.Message})
+		}
+
+		// События, нарушающие правила, не применяются, чтобы не искажать дальнейшие проверки
+		apply := true
+		for _, problem := range ctrl.Check(record.Event) {
+			issues = append(issues, Issue{File: eventsPath, Line: record.Line, Kind: KindRule, Severity: problem.Severity, Message: problem.Message})
+			if problem.Severity == race.SeverityError {
+				apply = false
+			}
+		}
+
+		if apply {
+			anomalies := len(ctrl.Anomalies)
+			ctrl.ProcessEvent(record.Event)
+			for _, anomaly := range ctrl.Anomalies[anomalies:] {
+				issues = append(issues, Issue{File: eventsPath, Line: record.Line, Kind: KindRule, Severity: race.SeverityWarning,
+					Message: fmt.Sprintf("competitor(%d) %s: %s", anomaly.CompetitorID, anomaly.Kind, anomaly.Message)})
+			}
+		}
+	}
+
+	for _, problem := range ctrl.CheckFinal() {
+		issues = append(issues, Issue{File: eventsPath, Line: lastLine[problem.CompetitorID], Kind: KindRule, Severity: problem.Severity, Message: problem.Message})
+	}
+
+	sort.SliceStable(issues, func(i, j int) bool {
+		return issues[i].Line < issues[j].Line
+	})
+
+	return issues
+}
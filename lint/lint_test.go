@@ -0,0 +1,121 @@
+package lint
+
+import (
+	"strings"
+	"testing"
+
+	"biathlon/config"
+	"biathlon/race"
+)
+
+var testConfig = &config.Config{
+	Laps:        1,
+	LapLen:      3000,
+	PenaltyLen:  150,
+	FiringLines: 1,
+	Start:       "10:00:00.000",
+	StartDelta:  "00:01:00",
+}
+
+func TestLintValidEvents(t *testing.T) {
+	data := `[09:00:00.000] 1 1
+[09:10:00.000] 2 1 10:00:00.000
+[10:00:01.000] 4 1
+[10:05:00.000] 5 1 1
+[10:05:01.000] 6 1 1
+[10:05:02.000] 6 1 2
+[10:05:03.000] 6 1 3
+[10:05:04.000] 6 1 4
+[10:05:05.000] 6 1 5
+[10:05:10.000] 7 1
+[10:12:00.000] 10 1
+`
+
+	if issues := Lint("config.json", testConfig, "events", []byte(data)); len(issues) != 0 {
+		t.Errorf("Expected no issues, got %v", issues)
+	}
+}
+
+func TestLintReportsEveryProblem(t *testing.T) {
+	data := `[09:00:00.000] 1 1
+[09:10:00.000] 2 1 10:00:00.000
+[09:10:01.000] 2 7 10:00:00.000
+[10:00:01.000] 4 1
+bogus line
+[09:59:00.000] 5 1 1
+[10:05:00.000] 6 1 1
+[10:05:01.000] 7 1
+[10:12:00.000] 10 1
+`
+
+	issues := Lint("config.json", testConfig, "events", []byte(data))
+
+	want := []struct {
+		line    int
+		message string
+	}{
+		{3, "unknown competitor(7)"},
+		{5, "invalid event format"},
+		{6, "before previous event time"},
+		{9, "missed 4 penalty loop(s)"},
+		{9, "never finished"},
+	}
+
+	if len(issues) != len(want) {
+		t.Fatalf("Expected %d issues, got %v", len(want), issues)
+	}
+	for i, w := range want {
+		if issues[i].Line != w.line || !strings.Contains(issues[i].Message, w.message) {
+			t.Errorf("Issue %d = %v, want line %d with %q", i, issues[i], w.line, w.message)
+		}
+	}
+	if !HasErrors(issues) {
+		t.Error("Expected errors")
+	}
+}
+
+func TestLintInvalidConfig(t *testing.T) {
+	cfg := *testConfig
+	cfg.Laps = 0
+
+	issues := Lint("config.json", &cfg, "events", []byte("[09:00:00.000] 1 1\n"))
+	if len(issues) != 1 || issues[0].File != "config.json" || issues[0].Severity != race.SeverityError {
+		t.Errorf("Expected one config error, got %v", issues)
+	}
+}
+
+func TestLintShootingOrder(t *testing.T) {
+	cfg := *testConfig
+	cfg.FiringPositions = []string{config.PositionProne}
+
+	data := `[09:00:00.000] 1 1
+[09:10:00.000] 2 1 10:00:00.000
+[10:00:01.000] 4 1
+[10:05:00.000] 5 1 1
+[10:05:01.000] 7 1
+[10:05:02.000] 8 1
+[10:08:00.000] 9 1
+[10:09:00.000] 5 1 1
+`
+
+	issues := Lint("config.json", &cfg, "events", []byte(data))
+	if len(issues) == 0 || issues[0].Line != 8 || !strings.Contains(issues[0].Message, "already completed all 1 shooting stages") {
+		t.Errorf("Expected shooting order error, got %v", issues)
+	}
+}
+
+func TestLintRangeLanes(t *testing.T) {
+	cfg := *testConfig
+	cfg.RangeLanes = 30
+
+	data := `[09:00:00.000] 1 1
+[09:10:00.000] 2 1 10:00:00.000
+[10:00:01.000] 4 1
+[10:05:00.000] 5 1 31
+`
+
+	issues := Lint("config.json", &cfg, "events", []byte(data))
+	if len(issues) == 0 || issues[0].Line != 4 || !strings.Contains(issues[0].Message, "lane 31 is out of range 1..30") {
+		t.Errorf("Expected lane error, got %v", issues)
+	}
+}
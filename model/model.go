@@ -8,26 +8,182 @@ import (
 const TimeFormat = "15:04:05.000"
 
 type LapInfo struct {
+	Time     string
+	Speed    float64
+	EndTime  string
+	Distance int
+}
+
+const (
+	SplitRangeArrival = "arrival"
+	SplitRangeExit    = "shooting"
+	SplitPenaltyExit  = "penalty"
+	SplitLapEnd       = "lap"
+)
+
+// Split - отметка времени участника на одной из точек хронометража
+type Split struct {
+	Kind    string
+	Index   int
+	Lap     int
 	Time    string
-	Speed   float64
-	EndTime string
+	Elapsed time.Duration
+	// Место в порядке прохождения точки в гонках с общим ходом (масс-старт, преследование); 0 - не отслеживается
+	Position int
+}
+
+func (s Split) Name() string {
+	if s.Kind == SplitRangeArrival {
+		return fmt.Sprintf("%s %d arrival", SplitRangeExit, s.Index)
+	}
+	return fmt.Sprintf("%s %d", s.Kind, s.Index)
+}
+
+const (
+	PointStart   = "start"
+	PointRange   = "range"
+	PointPenalty = "penalty"
+	PointFinish  = "finish"
+)
+
+// Segment - участок круга между двумя точками трассы без учёта времени на рубеже и штрафных кругах
+type Segment struct {
+	Lap      int
+	From     string
+	To       string
+	Distance int
+	Time     time.Duration
+	Speed    float64
+}
+
+func (s Segment) Name() string {
+	return s.From + "-" + s.To
+}
+
+const (
+	StatusFinished     = "Finished"
+	StatusNotStarted   = "NotStarted"   // DNS
+	StatusNotFinished  = "NotFinished"  // DNF
+	StatusDisqualified = "Disqualified" // DSQ
+
+	// Статусы в протоколе для участников, у которых итоговый статус ещё не определён
+	StatusRunning    = "Running"    // стартовал, но не финишировал
+	StatusRegistered = "Registered" // не стартовал
+)
+
+// ShortStatus возвращает принятое сокращение статуса: DNS, DNF или DSQ
+func ShortStatus(status string) string {
+	switch status {
+	case StatusNotStarted:
+		return "DNS"
+	case StatusNotFinished:
+		return "DNF"
+	case StatusDisqualified:
+		return "DSQ"
+	default:
+		return status
+	}
+}
+
+// Коды причин дисквалификации, которые проставляются автоматически. Жюри может указывать любые другие коды.
+const (
+	ReasonMissedFiringLine = "MissedFiringLine"
+	ReasonMissedPenalty    = "MissedPenalty"
+)
+
+type Disqualification struct {
+	Reason         string
+	Note           string
+	Time           string
+	PreviousStatus string
+}
+
+type JuryDecision struct {
+	Time    string
+	Action  string
+	Reason  string
+	Penalty time.Duration
+	Note    string
+}
+
+// TimeAdjustment - штраф (положительный) или бонус (отрицательный) ко времени по решению жюри
+type TimeAdjustment struct {
+	Time     string
+	Duration time.Duration
+	Reason   string
+	Note     string
+}
+
+const (
+	AnomalyLapSpeed        = "lap speed"
+	AnomalyPenaltySpeed    = "penalty speed"
+	AnomalyRangeTime       = "range time"
+	AnomalyMissingShooting = "missing shooting"
+	AnomalyFinishTime      = "finish time"
+	AnomalyRangeLane       = "range lane"
+)
+
+// Overtake - обгон: участник прошёл точку хронометража раньше соперника, шедшего впереди на предыдущей точке
+type Overtake struct {
+	Time         string
+	Point        string
+	CompetitorID int
+	OvertakenID  int
+}
+
+// Anomaly - подозрительное значение хронометража, например сбой чипа
+type Anomaly struct {
+	Time         string
+	CompetitorID int
+	Kind         string
+	Message      string
+}
+
+// RangeVisit - одно посещение огневого рубежа
+type RangeVisit struct {
+	Lap          int
+	FiringRange  int
+	Position     string
+	ArrivalTime  string
+	ExitTime     string
+	FirstHitTime string
+	LastHitTime  string
+	Hits         int
+	Targets      map[int]bool
+	RangeTime    time.Duration
+	ShootingTime time.Duration
 }
 
 type Competitor struct {
 	ID                int
 	RegisterTime      string
 	PlannedStartTime  string
+	RaceStartTime     string // начало отсчёта итогового времени, если оно отличается от PlannedStartTime
 	ActualStartTime   string
 	PenaltyStartTime  string
 	PenaltyDuration   time.Duration
 	PenaltySpeed      float64
 	EndTime           string
-	Status            string // "Finished", "NotStarted", "NotFinished"
+	FinishOrder       int    // порядок пересечения финиша, с 1; фотофиниш может переставить участника вперёд
+	Status            string // StatusFinished, StatusNotStarted, StatusNotFinished, StatusDisqualified
+	Disqualification  *Disqualification
+	Decisions         []JuryDecision
+	TimeAdjustments   []TimeAdjustment
 	LapTimes          []LapInfo
+	Splits            []Split
+	RangeVisits       []RangeVisit
+	SkiTime           time.Duration
+	Segments          []Segment
+	SegmentStartTime  string
+	SegmentFrom       string
+	SegmentPosition   int
 	HitsCount         int
 	ShotsCount        int
 	FiringRangeVisits map[int]bool
 	IsOnFiringRange   bool
+	IsOnPenalty       bool
+	PendingPenalty    int
+	SkippedPenalty    int
 	CurrentLap        int
 	LastEvent         time.Time
 	CannotContinue    string
@@ -44,6 +200,46 @@ func NewCompetitor(id int, registerTime string, laps int) *Competitor {
 	}
 }
 
+// CurrentVisit возвращает последнее посещение огневого рубежа или nil
+func (c *Competitor) CurrentVisit() *RangeVisit {
+	if len(c.RangeVisits) == 0 {
+		return nil
+	}
+	return &c.RangeVisits[len(c.RangeVisits)-1]
+}
+
+// CurrentSplit возвращает последнюю отметку хронометража или nil
+func (c *Competitor) CurrentSplit() *Split {
+	if len(c.Splits) == 0 {
+		return nil
+	}
+	return &c.Splits[len(c.Splits)-1]
+}
+
+// TotalRangeTime возвращает суммарное время на огневых рубежах
+func (c *Competitor) TotalRangeTime() time.Duration {
+	var total time.Duration
+	for _, visit := range c.RangeVisits {
+		total += visit.RangeTime
+	}
+	return total
+}
+
+// TotalAdjustment возвращает сумму штрафов и бонусов жюри
+func (c *Competitor) TotalAdjustment() time.Duration {
+	var total time.Duration
+	for _, adjustment := range c.TimeAdjustments {
+		total += adjustment.Duration
+	}
+	return total
+}
+
+const TargetsPerVisit = 5
+
+func IsValidTarget(target int) bool {
+	return target >= 1 && target <= TargetsPerVisit
+}
+
 func ParseTime(timeStr string) (time.Time, error) {
 	return time.Parse(TimeFormat, timeStr)
 }
@@ -55,3 +251,11 @@ func FormatDuration(d time.Duration) string {
 	milliseconds := int(d.Milliseconds()) % 1000
 	return fmt.Sprintf("%02d:%02d:%02d.%03d", hours, minutes, seconds, milliseconds)
 }
+
+// FormatSignedDuration форматирует длительность со знаком: +HH:MM:SS.sss или -HH:MM:SS.sss
+func FormatSignedDuration(d time.Duration) string {
+	if d < 0 {
+		return "-" + FormatDuration(-d)
+	}
+	return "+" + FormatDuration(d)
+}
@@ -0,0 +1,68 @@
+package race
+
+import (
+	"fmt"
+	"time"
+
+	"biathlon/event"
+	"biathlon/model"
+)
+
+// reportAnomaly сохраняет аномалию и пишет предупреждение в лог
+func (c *Controller) reportAnomaly(competitorID int, timeStr, kind, format string, args ...any) {
+	anomaly := model.Anomaly{
+		Time:         timeStr,
+		CompetitorID: competitorID,
+		Kind:         kind,
+		Message:      fmt.Sprintf(format, args...),
+	}
+	c.Anomalies = append(c.Anomalies, anomaly)
+
+	c.logEvent(event.Event{
+		Time:         timeStr,
+		EventID:      event.Anomaly,
+		CompetitorID: competitorID,
+		ExtraParams:  fmt.Sprintf("%s: %s", kind, anomaly.Message),
+	}, true)
+}
+
+func (c *Controller) checkSpeed(competitorID int, timeStr, kind, what string, speed float64) {
+	minSpeed, maxSpeed := c.Config.SpeedLimits()
+	if speed < minSpeed || speed > maxSpeed {
+		c.reportAnomaly(competitorID, timeStr, kind, "%s speed %.3f m/s is outside %.3f..%.3f", what, speed, minSpeed, maxSpeed)
+	}
+}
+
+func (c *Controller) checkLap(competitor *model.Competitor, lap int, timeStr string, speed float64) {
+	c.checkSpeed(competitor.ID, timeStr, model.AnomalyLapSpeed, fmt.Sprintf("lap %d", lap), speed)
+
+	if !c.Config.HasShooting(lap) {
+		return
+	}
+	for _, visit := range competitor.RangeVisits {
+		if visit.Lap == lap {
+			return
+		}
+	}
+	c.reportAnomaly(competitor.ID, timeStr, model.AnomalyMissingShooting, "lap %d completed without a firing range visit", lap)
+}
+
+func (c *Controller) checkRangeVisit(competitor *model.Competitor, visit *model.RangeVisit, timeStr string) {
+	minRangeTime := c.Config.MinRangeDuration()
+	if minRangeTime > 0 && visit.RangeTime < minRangeTime {
+		c.reportAnomaly(competitor.ID, timeStr, model.AnomalyRangeTime, "range visit %s is shorter than %s",
+			model.FormatDuration(visit.RangeTime), model.FormatDuration(minRangeTime))
+	}
+}
+
+// checkFinish проверяет, что дистанцию нельзя было пройти быстрее, чем на максимальной скорости
+func (c *Controller) checkFinish(competitor *model.Competitor, timeStr string) {
+	_, maxSpeed := c.Config.SpeedLimits()
+	minTime := time.Duration(float64(c.Config.TotalDistance()) / maxSpeed * float64(time.Second))
+
+	raceTime := durationBetween(competitor.ActualStartTime, timeStr)
+	if competitor.ActualStartTime != "" && raceTime < minTime {
+		c.reportAnomaly(competitor.ID, timeStr, model.AnomalyFinishTime, "race time %s is less than minimum possible %s",
+			model.FormatDuration(raceTime), model.FormatDuration(minTime))
+	}
+}
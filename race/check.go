@@ -0,0 +1,213 @@
+package race
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"biathlon/event"
+	"biathlon/model"
+)
+
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+)
+
+type Problem struct {
+	Severity     Severity
+	CompetitorID int
+	Message      string
+}
+
+func errorf(competitorID int, format string, args ...any) Problem {
+	return Problem{Severity: SeverityError, CompetitorID: competitorID, Message: fmt.Sprintf(format, args...)}
+}
+
+func warningf(competitorID int, format string, args ...any) Problem {
+	return Problem{Severity: SeverityWarning, CompetitorID: competitorID, Message: fmt.Sprintf(format, args...)}
+}
+
+// Check проверяет, допустимо ли событие в текущем состоянии гонки.
+// Вызывается до ProcessEvent и не меняет состояние контроллера.
+func (c *Controller) Check(evt event.Event) []Problem {
+	var problems []Problem
+	id := evt.CompetitorID
+
+	if !event.IsIncoming(evt.EventID) && evt.EventID != event.Disqualified {
+		return append(problems, errorf(id, "unknown incoming event ID %d", evt.EventID))
+	}
+
+	competitor, exists := c.Competitors[id]
+	if evt.EventID == event.Registered {
+		if exists {
+			problems = append(problems, errorf(id, "competitor(%d) is already registered", id))
+		}
+		return problems
+	}
+	if !exists {
+		return append(problems, errorf(id, "unknown competitor(%d)", id))
+	}
+
+	// Решения жюри и фотофиниш допустимы и после финиша
+	switch evt.EventID {
+	case event.JuryDecision:
+		return append(problems, c.checkJuryDecision(competitor, evt)...)
+	case event.PhotoFinish:
+		return append(problems, c.checkPhotoFinish(competitor, evt)...)
+	}
+
+	if competitor.Status != "" {
+		return append(problems, errorf(id, "competitor(%d) already has status %s", id, competitor.Status))
+	}
+
+	started := competitor.ActualStartTime != ""
+
+	switch evt.EventID {
+	case event.StartTimeSet:
+		if _, err := model.ParseTime(evt.ExtraParams); err != nil {
+			problems = append(problems, errorf(id, "invalid start time %q", evt.ExtraParams))
+		}
+		if started {
+			problems = append(problems, errorf(id, "start time set after competitor(%d) has started", id))
+		}
+		if c.Config.IsMassStart() && evt.ExtraParams != competitor.PlannedStartTime {
+			problems = append(problems, warningf(id, "start time %s differs from mass start time %s and is ignored", evt.ExtraParams, competitor.PlannedStartTime))
+		}
+	case event.OnStartLine:
+		if competitor.PlannedStartTime == "" {
+			problems = append(problems, warningf(id, "competitor(%d) is on the start line without a start time", id))
+		}
+	case event.Started:
+		if competitor.PlannedStartTime == "" {
+			problems = append(problems, errorf(id, "competitor(%d) started without a start time", id))
+		} else if planned, err := model.ParseTime(competitor.PlannedStartTime); err == nil {
+			if eventTime, err := evt.ParsedTime(); err == nil && eventTime.Before(planned) {
+				problems = append(problems, warningf(id, "competitor(%d) started before planned start time %s", id, competitor.PlannedStartTime))
+			}
+		}
+		if started {
+			problems = append(problems, errorf(id, "competitor(%d) has already started", id))
+		}
+	case event.OnFiringRange:
+		if firingRange, err := strconv.Atoi(evt.ExtraParams); err != nil || firingRange < 1 {
+			problems = append(problems, errorf(id, "invalid firing range %q", evt.ExtraParams))
+		} else if c.Config.RangeLanes > 0 && firingRange > c.Config.RangeLanes {
+			problems = append(problems, errorf(id, "firing range lane %d is out of range 1..%d", firingRange, c.Config.RangeLanes))
+		}
+		switch {
+		case !started:
+			problems = append(problems, errorf(id, "competitor(%d) is on the firing range before start", id))
+		case competitor.IsOnFiringRange:
+			problems = append(problems, errorf(id, "competitor(%d) is already on the firing range", id))
+		case competitor.IsOnPenalty:
+			problems = append(problems, errorf(id, "competitor(%d) is on the firing range while on penalty laps", id))
+		}
+		if order := c.Config.ShootingOrder(); len(order) > 0 && len(competitor.RangeVisits) >= len(order) {
+			problems = append(problems, errorf(id, "competitor(%d) already completed all %d shooting stages (%s)", id, len(order), strings.Join(order, ",")))
+		}
+	case event.TargetHit:
+		target, err := strconv.Atoi(evt.ExtraParams)
+		if err != nil || !model.IsValidTarget(target) {
+			problems = append(problems, errorf(id, "invalid target %q, expected 1..%d", evt.ExtraParams, model.TargetsPerVisit))
+		}
+		if !competitor.IsOnFiringRange {
+			problems = append(problems, errorf(id, "target hit by competitor(%d) outside the firing range", id))
+		} else if visit := competitor.CurrentVisit(); visit != nil && visit.Targets[target] {
+			problems = append(problems, warningf(id, "target(%d) already hit by competitor(%d) on this visit, hit ignored", target, id))
+		}
+	case event.LeftFiringRange:
+		if !competitor.IsOnFiringRange {
+			problems = append(problems, errorf(id, "competitor(%d) left the firing range without entering it", id))
+		}
+	case event.EnteredPenalty:
+		switch {
+		case !started:
+			problems = append(problems, errorf(id, "competitor(%d) entered the penalty laps before start", id))
+		case competitor.IsOnFiringRange:
+			problems = append(problems, errorf(id, "competitor(%d) entered the penalty laps without leaving the firing range", id))
+		case competitor.IsOnPenalty:
+			problems = append(problems, errorf(id, "competitor(%d) is already on the penalty laps", id))
+		case competitor.PendingPenalty == 0:
+			problems = append(problems, warningf(id, "competitor(%d) entered the penalty laps without missed shots", id))
+		}
+	case event.LeftPenalty:
+		if !competitor.IsOnPenalty {
+			problems = append(problems, errorf(id, "competitor(%d) left the penalty laps without entering them", id))
+		}
+	case event.EndedLap:
+		switch {
+		case !started:
+			problems = append(problems, errorf(id, "competitor(%d) ended the main lap before start", id))
+		case competitor.IsOnFiringRange:
+			problems = append(problems, errorf(id, "competitor(%d) ended the main lap while on the firing range", id))
+		case competitor.IsOnPenalty:
+			problems = append(problems, errorf(id, "competitor(%d) ended the main lap while on the penalty laps", id))
+		case competitor.PendingPenalty > 0:
+			problems = append(problems, errorf(id, "competitor(%d) missed %d penalty loop(s)", id, competitor.PendingPenalty))
+		case c.Config.HasShooting(competitor.CurrentLap) && !shotOnLap(competitor, competitor.CurrentLap):
+			problems = append(problems, errorf(id, "competitor(%d) ended lap %d without a firing range visit", id, competitor.CurrentLap))
+		}
+	}
+
+	return problems
+}
+
+func shotOnLap(competitor *model.Competitor, lap int) bool {
+	for _, visit := range competitor.RangeVisits {
+		if visit.Lap == lap {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *Controller) checkJuryDecision(competitor *model.Competitor, evt event.Event) []Problem {
+	id := competitor.ID
+
+	decision, err := event.ParseJuryDecision(evt.ExtraParams)
+	if err != nil {
+		return []Problem{errorf(id, "invalid jury decision: %v", err)}
+	}
+
+	switch decision.Action {
+	case event.JuryDisqualify:
+		if competitor.Status == model.StatusDisqualified {
+			return []Problem{warningf(id, "competitor(%d) is already disqualified", id)}
+		}
+	case event.JuryReinstate:
+		if competitor.Disqualification == nil {
+			return []Problem{errorf(id, "competitor(%d) is not disqualified and cannot be reinstated", id)}
+		}
+	}
+
+	return nil
+}
+
+// CheckOrder проверяет, что события идут по неубыванию времени
+func (c *Controller) CheckOrder(evt event.Event) []Problem {
+	eventTime, err := evt.ParsedTime()
+	if err != nil || c.lastTime.IsZero() || !eventTime.Before(c.lastTime) {
+		return nil
+	}
+	return []Problem{errorf(evt.CompetitorID, "event time %s is before previous event time %s", evt.Time, c.lastTime.Format(model.TimeFormat))}
+}
+
+// CheckFinal проверяет состояние участников после обработки всех событий
+func (c *Controller) CheckFinal() []Problem {
+	var problems []Problem
+
+	for _, id := range c.sortedIDs() {
+		competitor := c.Competitors[id]
+		switch {
+		case competitor.PlannedStartTime == "":
+			problems = append(problems, warningf(id, "competitor(%d) has no start time", id))
+		case competitor.ActualStartTime != "" && competitor.Status == "":
+			problems = append(problems, errorf(id, "competitor(%d) never finished", id))
+		}
+	}
+
+	return problems
+}
@@ -0,0 +1,70 @@
+package race
+
+import (
+	"biathlon/event"
+	"biathlon/model"
+)
+
+func (c *Controller) juryDecision(evt event.Event) {
+	competitor, exists := c.Competitors[evt.CompetitorID]
+	if !exists {
+		return
+	}
+
+	decision, err := event.ParseJuryDecision(evt.ExtraParams)
+	if err != nil {
+		return
+	}
+
+	competitor.Decisions = append(competitor.Decisions, model.JuryDecision{
+		Time:    evt.Time,
+		Action:  decision.Action,
+		Reason:  decision.Reason,
+		Penalty: decision.Penalty,
+		Note:    decision.Note,
+	})
+
+	switch decision.Action {
+	case event.JuryDisqualify:
+		c.disqualify(competitor, decision.Reason, decision.Note, evt.Time)
+	case event.JuryReinstate:
+		c.reinstate(competitor)
+	case event.JuryPenalty:
+		competitor.TimeAdjustments = append(competitor.TimeAdjustments, model.TimeAdjustment{
+			Time:     evt.Time,
+			Duration: decision.Penalty,
+			Reason:   decision.Reason,
+			Note:     decision.Note,
+		})
+	}
+}
+
+// disqualify снимает участника с причиной и запоминает прежний статус для возможного восстановления
+func (c *Controller) disqualify(competitor *model.Competitor, reason, note, timeStr string) {
+	if competitor.Status == model.StatusDisqualified {
+		return
+	}
+
+	competitor.Disqualification = &model.Disqualification{
+		Reason:         reason,
+		Note:           note,
+		Time:           timeStr,
+		PreviousStatus: competitor.Status,
+	}
+	competitor.Status = model.StatusDisqualified
+
+	c.logEvent(event.Event{
+		Time:         timeStr,
+		EventID:      event.Disqualified,
+		CompetitorID: competitor.ID,
+	}, true)
+}
+
+func (c *Controller) reinstate(competitor *model.Competitor) {
+	if competitor.Disqualification == nil {
+		return
+	}
+
+	competitor.Status = competitor.Disqualification.PreviousStatus
+	competitor.Disqualification = nil
+}
@@ -0,0 +1,59 @@
+package race
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"biathlon/event"
+)
+
+type LogEntry struct {
+	Time         string `json:"time"`
+	EventID      int    `json:"eventId"`
+	CompetitorID int    `json:"competitorId"`
+	Payload      any    `json:"payload,omitempty"`
+	Generated    bool   `json:"generated"`
+	Status       string `json:"status"`
+}
+
+// logEvent пишет событие одновременно в текстовый лог и в структурированный
+func (c *Controller) logEvent(evt event.Event, generated bool) int {
+	c.OutputLog = append(c.OutputLog, fmt.Sprintf("[%s] %s", evt.Time, event.FormatLogEntry(evt)))
+	c.Entries = append(c.Entries, LogEntry{
+		Time:         evt.Time,
+		EventID:      evt.EventID,
+		CompetitorID: evt.CompetitorID,
+		Payload:      evt.Payload(),
+		Generated:    generated,
+		Status:       c.competitorStatus(evt.CompetitorID),
+	})
+	return len(c.Entries) - 1
+}
+
+func (c *Controller) competitorStatus(competitorID int) string {
+	competitor, exists := c.Competitors[competitorID]
+	switch {
+	case !exists:
+		return ""
+	case competitor.Status != "":
+		return competitor.Status
+	case competitor.ActualStartTime != "":
+		return "Running"
+	default:
+		return "Registered"
+	}
+}
+
+func (c *Controller) JSONLog() (string, error) {
+	var log strings.Builder
+	for _, entry := range c.Entries {
+		data, err := json.Marshal(entry)
+		if err != nil {
+			return "", err
+		}
+		log.Write(data)
+		log.WriteByte('\n')
+	}
+	return log.String(), nil
+}
@@ -0,0 +1,80 @@
+package race
+
+import (
+	"strconv"
+
+	"biathlon/event"
+	"biathlon/model"
+)
+
+// expectedLane возвращает установку, на которой участник масс-старта должен стрелять на следующем рубеже:
+// на первом - по стартовому номеру, дальше - по порядку прихода на рубеж
+func (c *Controller) expectedLane(competitor *model.Competitor) int {
+	stage := len(competitor.RangeVisits) + 1
+
+	lane := competitor.ID
+	if stage > 1 {
+		lane = c.stageArrivals[stage] + 1
+	}
+	if c.Config.RangeLanes > 0 {
+		lane = (lane-1)%c.Config.RangeLanes + 1
+	}
+	return lane
+}
+
+func (c *Controller) checkLane(competitor *model.Competitor, lane int, timeStr string) {
+	stage := len(competitor.RangeVisits) + 1
+	if expected := c.expectedLane(competitor); lane != expected {
+		c.reportAnomaly(competitor.ID, timeStr, model.AnomalyRangeLane, "stage %d shot on lane %d, expected lane %d", stage, lane, expected)
+	}
+
+	if c.stageArrivals == nil {
+		c.stageArrivals = make(map[int]int)
+	}
+	c.stageArrivals[stage]++
+}
+
+// photoFinish ставит участника события непосредственно перед участником из параметров, если финиш
+// зафиксировал их наоборот; финишировавшие между ними сдвигаются на одно место назад
+func (c *Controller) photoFinish(evt event.Event) {
+	behindID, _ := strconv.Atoi(evt.ExtraParams)
+	ahead, exists := c.Competitors[evt.CompetitorID]
+	behind, behindExists := c.Competitors[behindID]
+	if !exists || !behindExists || ahead.FinishOrder == 0 || behind.FinishOrder == 0 {
+		return
+	}
+
+	if ahead.FinishOrder > behind.FinishOrder {
+		from, to := ahead.FinishOrder, behind.FinishOrder
+		for _, other := range c.Competitors {
+			if other.FinishOrder >= to && other.FinishOrder < from {
+				other.FinishOrder++
+			}
+		}
+		ahead.FinishOrder = to
+		if c.Config.IsHeadToHead() {
+			c.moveFinishPosition(ahead, behind, evt.Time)
+		}
+	}
+}
+
+func (c *Controller) checkPhotoFinish(competitor *model.Competitor, evt event.Event) []Problem {
+	id := competitor.ID
+
+	behindID, err := strconv.Atoi(evt.ExtraParams)
+	if err != nil {
+		return []Problem{errorf(id, "invalid photo finish competitor %q", evt.ExtraParams)}
+	}
+	behind, exists := c.Competitors[behindID]
+	switch {
+	case !exists:
+		return []Problem{errorf(id, "unknown competitor(%d) in photo finish", behindID)}
+	case behindID == id:
+		return []Problem{errorf(id, "photo finish compares competitor(%d) with itself", id)}
+	case competitor.FinishOrder == 0 || behind.FinishOrder == 0:
+		return []Problem{errorf(id, "photo finish between competitor(%d) and competitor(%d) before both finished", id, behindID)}
+	case competitor.EndTime != behind.EndTime:
+		return []Problem{warningf(id, "photo finish between competitor(%d) and competitor(%d) with different finish times", id, behindID)}
+	}
+	return nil
+}
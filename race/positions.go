@@ -0,0 +1,115 @@
+package race
+
+import (
+	"strconv"
+
+	"biathlon/event"
+	"biathlon/model"
+)
+
+// trackPosition отмечает место участника в порядке прохождения последней точки хронометража и фиксирует обгоны:
+// участник обогнал тех, кто прошёл его предыдущую точку раньше него, но эту ещё не прошёл и продолжает гонку
+func (c *Controller) trackPosition(competitor *model.Competitor, timeStr string) {
+	if c.passed == nil {
+		c.passed = make(map[string][]int)
+	}
+
+	last := len(competitor.Splits) - 1
+	split := &competitor.Splits[last]
+	name := split.Name()
+	c.passed[name] = append(c.passed[name], competitor.ID)
+	split.Position = len(c.passed[name])
+
+	var previous *model.Split
+	for i := last - 1; i >= 0; i-- {
+		if competitor.Splits[i].Position > 0 {
+			previous = &competitor.Splits[i]
+			break
+		}
+	}
+	if previous == nil {
+		return
+	}
+
+	passedHere := make(map[int]bool)
+	for _, id := range c.passed[name] {
+		passedHere[id] = true
+	}
+
+	for _, id := range c.passed[previous.Name()] {
+		if id == competitor.ID {
+			break
+		}
+		if passedHere[id] || c.Competitors[id].Status != "" {
+			continue
+		}
+
+		c.Overtakes = append(c.Overtakes, model.Overtake{Time: timeStr, Point: name, CompetitorID: competitor.ID, OvertakenID: id})
+		c.logEvent(event.Event{
+			Time:         timeStr,
+			EventID:      event.Overtake,
+			CompetitorID: competitor.ID,
+			ExtraParams:  strconv.Itoa(id),
+		}, true)
+	}
+}
+
+// moveFinishPosition исправляет по фотофинишу места на финише: участник ahead встаёт перед behind,
+// а обгоны на финише между ahead и сдвинутыми назад участниками пересчитываются. Уже записанный в лог
+// обгон не удаляется из него, а отменяется событием 36.
+func (c *Controller) moveFinishPosition(ahead, behind *model.Competitor, timeStr string) {
+	a, b := ahead.CurrentSplit(), behind.CurrentSplit()
+	if a == nil || b == nil || b.Position == 0 || a.Position <= b.Position || a.Name() != b.Name() {
+		return
+	}
+
+	name := a.Name()
+	order := c.passed[name]
+	from, to := a.Position-1, b.Position-1
+	moved := append([]int(nil), order[to:from]...)
+	copy(order[to+1:from+1], moved)
+	order[to] = ahead.ID
+	for i := to; i <= from; i++ {
+		c.Competitors[order[i]].CurrentSplit().Position = i + 1
+	}
+
+	wasAhead := make(map[int]bool)
+	for _, id := range moved {
+		wasAhead[id] = true
+	}
+	overtakes := c.Overtakes[:0]
+	for _, overtake := range c.Overtakes {
+		if overtake.Point != name || overtake.OvertakenID != ahead.ID || !wasAhead[overtake.CompetitorID] {
+			overtakes = append(overtakes, overtake)
+			continue
+		}
+		c.logEvent(event.Event{Time: timeStr, EventID: event.OvertakeVoided, CompetitorID: overtake.CompetitorID, ExtraParams: strconv.Itoa(ahead.ID)}, true)
+	}
+	c.Overtakes = overtakes
+
+	// Обгон на финише засчитывается, если на предыдущей точке участник шёл позади
+	for _, id := range moved {
+		if c.passedEarlier(ahead, id) {
+			c.Overtakes = append(c.Overtakes, model.Overtake{Time: timeStr, Point: name, CompetitorID: ahead.ID, OvertakenID: id})
+			c.logEvent(event.Event{Time: timeStr, EventID: event.Overtake, CompetitorID: ahead.ID, ExtraParams: strconv.Itoa(id)}, true)
+		}
+	}
+}
+
+// passedEarlier сообщает, прошёл ли участник otherID предыдущую точку хронометража competitor раньше него
+func (c *Controller) passedEarlier(competitor *model.Competitor, otherID int) bool {
+	for i := len(competitor.Splits) - 2; i >= 0; i-- {
+		if previous := competitor.Splits[i]; previous.Position > 0 {
+			for _, id := range c.passed[previous.Name()] {
+				if id == competitor.ID {
+					return false
+				}
+				if id == otherID {
+					return true
+				}
+			}
+			return false
+		}
+	}
+	return false
+}
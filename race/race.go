@@ -1,7 +1,7 @@
 package race
 
 import (
-	"fmt"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -16,6 +16,18 @@ type Controller struct {
 	Config      *config.Config
 	Competitors map[int]*model.Competitor
 	OutputLog   []string
+	Entries     []LogEntry
+	Anomalies   []model.Anomaly
+
+	// Обгоны в гонках с общим ходом
+	Overtakes []model.Overtake
+
+	lastTime time.Time
+	// Масс-старт: число финишировавших и число пришедших на каждый рубеж
+	finishCount   int
+	stageArrivals map[int]int
+	// Порядок прохождения точек хронометража: номера участников по имени точки
+	passed map[string][]int
 }
 
 func NewController(cfg *config.Config) *Controller {
@@ -23,28 +35,51 @@ func NewController(cfg *config.Config) *Controller {
 		Config:      cfg,
 		Competitors: make(map[int]*model.Competitor),
 		OutputLog:   []string{},
+		Entries:     []LogEntry{},
+		Anomalies:   []model.Anomaly{},
 	}
 }
 
 func (c *Controller) ProcessEvents(events []event.Event) (string, error) {
 	for _, evt := range events {
-		if err := c.processEvent(evt); err != nil {
+		if err := c.ProcessEvent(evt); err != nil {
 			return "", err
 		}
 	}
 
-	for id, competitor := range c.Competitors {
+	c.Finalize()
+
+	return strings.Join(c.OutputLog, "\n"), nil
+}
+
+func (c *Controller) ProcessEvent(evt event.Event) error {
+	return c.processEvent(evt)
+}
+
+// Finalize дисквалифицирует участников, которые так и не стартовали
+func (c *Controller) Finalize() {
+	for _, id := range c.sortedIDs() {
+		competitor := c.Competitors[id]
 		if competitor.PlannedStartTime != "" && competitor.ActualStartTime == "" {
 			c.disqualifyCompetitor(id, nil)
 		}
 	}
+}
 
-	return strings.Join(c.OutputLog, "\n"), nil
+func (c *Controller) sortedIDs() []int {
+	ids := make([]int, 0, len(c.Competitors))
+	for id := range c.Competitors {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	return ids
 }
 
 func (c *Controller) processEvent(evt event.Event) error {
-	logEntry := event.FormatLogEntry(evt)
-	c.OutputLog = append(c.OutputLog, fmt.Sprintf("[%s] %s", evt.Time, logEntry))
+	entry := c.logEvent(evt, false)
+	if eventTime, err := evt.ParsedTime(); err == nil {
+		c.lastTime = eventTime
+	}
 
 	switch evt.EventID {
 	case event.Registered:
@@ -57,12 +92,12 @@ func (c *Controller) processEvent(evt event.Event) error {
 		c.startCompetitor(evt)
 	case event.OnFiringRange:
 		firingRange, _ := strconv.Atoi(evt.ExtraParams)
-		c.competitorOnFiringRange(evt.CompetitorID, firingRange)
+		c.competitorOnFiringRange(evt.CompetitorID, firingRange, evt.Time)
 	case event.TargetHit:
 		target, _ := strconv.Atoi(evt.ExtraParams)
-		c.targetHit(evt.CompetitorID, target)
+		c.targetHit(evt.CompetitorID, target, evt.Time)
 	case event.LeftFiringRange:
-		c.competitorLeftFiringRange(evt.CompetitorID)
+		c.competitorLeftFiringRange(evt.CompetitorID, evt.Time)
 	case event.EnteredPenalty:
 		c.competitorEnteredPenaltyLaps(evt.CompetitorID, evt.Time)
 	case event.LeftPenalty:
@@ -71,52 +106,128 @@ func (c *Controller) processEvent(evt event.Event) error {
 		c.competitorEndedMainLap(evt.CompetitorID, evt.Time)
 	case event.CannotContinue:
 		c.competitorCannotContinue(evt.CompetitorID, evt.ExtraParams)
+	case event.JuryDecision:
+		c.juryDecision(evt)
+	case event.PhotoFinish:
+		c.photoFinish(evt)
 	case event.Disqualified:
 		c.disqualifyCompetitor(evt.CompetitorID, &evt.Time)
 	}
 
+	c.Entries[entry].Status = c.competitorStatus(evt.CompetitorID)
+
 	return nil
 }
 
 func (c *Controller) registerCompetitor(evt event.Event) {
-	c.Competitors[evt.CompetitorID] = model.NewCompetitor(evt.CompetitorID, evt.Time, c.Config.Laps)
+	competitor := model.NewCompetitor(evt.CompetitorID, evt.Time, c.Config.Laps)
+	switch c.Config.Mode {
+	case config.ModeMassStart:
+		competitor.PlannedStartTime = c.raceStartTime()
+	case config.ModePursuit:
+		competitor.RaceStartTime = c.raceStartTime()
+	}
+	c.Competitors[evt.CompetitorID] = competitor
 }
 
+// В масс-старте время старта общее и результаты жеребьёвки его не меняют
 func (c *Controller) setCompetitorStartTime(evt event.Event) {
-	if competitor, exists := c.Competitors[evt.CompetitorID]; exists {
+	if competitor, exists := c.Competitors[evt.CompetitorID]; exists && !c.Config.IsMassStart() {
 		competitor.PlannedStartTime = evt.ExtraParams
 	}
 }
 
+func (c *Controller) raceStartTime() string {
+	start, err := config.ParseClock(c.Config.Start)
+	if err != nil {
+		return c.Config.Start
+	}
+	return start.Format(model.TimeFormat)
+}
+
 func (c *Controller) startCompetitor(evt event.Event) {
 	if competitor, exists := c.Competitors[evt.CompetitorID]; exists {
 		competitor.ActualStartTime = evt.Time
+		if c.Config.Course != nil {
+			c.passPoint(competitor, model.PointStart, 0, evt.Time, false)
+		}
 	}
 }
 
-func (c *Controller) competitorOnFiringRange(competitorID, firingRange int) {
+func (c *Controller) competitorOnFiringRange(competitorID, firingRange int, timeStr string) {
 	if competitor, exists := c.Competitors[competitorID]; exists {
+		if c.Config.IsMassStart() {
+			c.checkLane(competitor, firingRange, timeStr)
+		}
 		competitor.IsOnFiringRange = true
 		competitor.FiringRangeVisits[firingRange] = true
+		position := ""
+		if order := c.Config.ShootingOrder(); len(competitor.RangeVisits) < len(order) {
+			position = order[len(competitor.RangeVisits)]
+		}
+
+		competitor.RangeVisits = append(competitor.RangeVisits, model.RangeVisit{
+			Lap:         competitor.CurrentLap,
+			FiringRange: firingRange,
+			Position:    position,
+			ArrivalTime: timeStr,
+			Targets:     make(map[int]bool),
+		})
+		c.recordSplit(competitor, model.SplitRangeArrival, len(competitor.RangeVisits), timeStr)
+		if c.Config.Course != nil {
+			c.passPoint(competitor, model.PointRange, c.Config.Course.RangeEntrance, timeStr, true)
+		}
 	}
 }
 
-func (c *Controller) targetHit(competitorID, target int) {
+func (c *Controller) targetHit(competitorID, target int, timeStr string) {
 	if competitor, exists := c.Competitors[competitorID]; exists && competitor.IsOnFiringRange {
+		// Участник на рубеже всегда имеет текущее посещение; выстрелы вне рубежа отмечает Check
+		visit := competitor.CurrentVisit()
+
+		// Повторное попадание в ту же мишень и несуществующие мишени не засчитываются
+		if !model.IsValidTarget(target) || visit.Targets[target] {
+			return
+		}
+
 		competitor.HitsCount++
+		visit.Hits++
+		visit.Targets[target] = true
+		if visit.FirstHitTime == "" {
+			visit.FirstHitTime = timeStr
+		}
+		visit.LastHitTime = timeStr
 	}
 }
 
-func (c *Controller) competitorLeftFiringRange(competitorID int) {
+func (c *Controller) competitorLeftFiringRange(competitorID int, timeStr string) {
 	if competitor, exists := c.Competitors[competitorID]; exists {
-		competitor.IsOnFiringRange = false
+		c.recordSplit(competitor, model.SplitRangeExit, len(competitor.RangeVisits), timeStr)
 		competitor.ShotsCount += 5
+
+		hits := 0
+		if visit := competitor.CurrentVisit(); visit != nil && competitor.IsOnFiringRange {
+			visit.ExitTime = timeStr
+			visit.RangeTime = durationBetween(visit.ArrivalTime, timeStr)
+			visit.ShootingTime = durationBetween(visit.FirstHitTime, visit.LastHitTime)
+			hits = visit.Hits
+			c.checkRangeVisit(competitor, visit, timeStr)
+		}
+		competitor.IsOnFiringRange = false
+		competitor.PendingPenalty += max(0, 5-hits)
+		if c.Config.Course != nil {
+			c.passPoint(competitor, model.PointRange, c.Config.RangeExit(), timeStr, false)
+		}
 	}
 }
 
 func (c *Controller) competitorEnteredPenaltyLaps(competitorID int, timeStr string) {
 	if competitor, exists := c.Competitors[competitorID]; exists {
 		competitor.PenaltyStartTime = timeStr
+		competitor.IsOnPenalty = true
+		if c.Config.Course != nil {
+			c.passPoint(competitor, model.PointPenalty, c.Config.Course.PenaltyEntrance, timeStr, true)
+		}
 	}
 }
 
@@ -125,7 +236,16 @@ func (c *Controller) competitorLeftPenaltyLaps(competitorID int, timeStr string)
 		eventTime, _ := model.ParseTime(timeStr)
 		penaltyStart, _ := model.ParseTime(competitor.PenaltyStartTime)
 
-		competitor.PenaltyDuration += eventTime.Sub(penaltyStart)
+		loopDuration := eventTime.Sub(penaltyStart)
+		checkSpeed := competitor.PendingPenalty > 0 && loopDuration.Seconds() > 0
+
+		competitor.PenaltyDuration += loopDuration
+		competitor.IsOnPenalty = false
+		competitor.PendingPenalty = 0
+		c.recordSplit(competitor, model.SplitPenaltyExit, len(competitor.RangeVisits), timeStr)
+		if c.Config.Course != nil {
+			c.passPoint(competitor, model.PointPenalty, c.Config.Course.PenaltyEntrance, timeStr, false)
+		}
 
 		penaltyDistance := float64(5*competitor.CurrentLap-competitor.HitsCount) * float64(c.Config.PenaltyLen)
 		speed := 0.0
@@ -134,6 +254,11 @@ func (c *Controller) competitorLeftPenaltyLaps(competitorID int, timeStr string)
 		}
 
 		competitor.PenaltySpeed = speed
+
+		// Проверяется та же скорость, что выводится в отчёте
+		if checkSpeed {
+			c.checkSpeed(competitorID, timeStr, model.AnomalyPenaltySpeed, "penalty loop", competitor.PenaltySpeed)
+		}
 	}
 }
 
@@ -149,20 +274,30 @@ func (c *Controller) competitorEndedMainLap(competitorID int, timeStr string) {
 		}
 
 		lapDuration := eventTime.Sub(startTime)
+		lapLen := c.Config.LapLength(competitor.CurrentLap)
 
 		speed := 0.0
 		if lapDuration.Seconds() > 0 {
-			speed = float64(c.Config.LapLen) / lapDuration.Seconds()
+			speed = float64(lapLen) / lapDuration.Seconds()
 		}
 
 		if competitor.CurrentLap <= len(competitor.LapTimes) {
 			competitor.LapTimes[competitor.CurrentLap-1] = model.LapInfo{
-				Time:    model.FormatDuration(lapDuration),
-				Speed:   speed,
-				EndTime: timeStr,
+				Time:     model.FormatDuration(lapDuration),
+				Speed:    speed,
+				EndTime:  timeStr,
+				Distance: lapLen,
 			}
 		}
 
+		c.checkLap(competitor, competitor.CurrentLap, timeStr, speed)
+		competitor.SkippedPenalty += competitor.PendingPenalty
+		competitor.PendingPenalty = 0
+		c.recordSplit(competitor, model.SplitLapEnd, competitor.CurrentLap, timeStr)
+		if c.Config.Course != nil {
+			c.passPoint(competitor, model.PointFinish, c.Config.CourseFinish(competitor.CurrentLap), timeStr, true)
+			c.passPoint(competitor, model.PointStart, 0, timeStr, false)
+		}
 		competitor.CurrentLap++
 
 		if competitor.CurrentLap > c.Config.Laps {
@@ -171,29 +306,119 @@ func (c *Controller) competitorEndedMainLap(competitorID int, timeStr string) {
 	}
 }
 
+// recordSplit сохраняет время прохождения точки хронометража относительно планового старта
+func (c *Controller) recordSplit(competitor *model.Competitor, kind string, index int, timeStr string) {
+	startStr := competitor.PlannedStartTime
+	if startStr == "" {
+		startStr = competitor.ActualStartTime
+	}
+	start, _ := model.ParseTime(startStr)
+	eventTime, _ := model.ParseTime(timeStr)
+
+	competitor.Splits = append(competitor.Splits, model.Split{
+		Kind:    kind,
+		Index:   index,
+		Lap:     competitor.CurrentLap,
+		Time:    timeStr,
+		Elapsed: eventTime.Sub(start),
+	})
+	if c.Config.IsHeadToHead() && kind != model.SplitPenaltyExit {
+		c.trackPosition(competitor, timeStr)
+	}
+}
+
+// passPoint отмечает прохождение точки трассы. Если record, то сохраняется участок от предыдущей точки.
+// После рубежа и штрафных кругов отсчёт начинается заново, поэтому время стояния на рубеже не учитывается.
+func (c *Controller) passPoint(competitor *model.Competitor, point string, position int, timeStr string, record bool) {
+	if record && competitor.SegmentStartTime != "" {
+		duration := durationBetween(competitor.SegmentStartTime, timeStr)
+		distance := position - competitor.SegmentPosition
+
+		speed := 0.0
+		if duration.Seconds() > 0 {
+			speed = float64(distance) / duration.Seconds()
+		}
+
+		competitor.Segments = append(competitor.Segments, model.Segment{
+			Lap:      competitor.CurrentLap,
+			From:     competitor.SegmentFrom,
+			To:       point,
+			Distance: distance,
+			Time:     duration,
+			Speed:    speed,
+		})
+	}
+
+	competitor.SegmentStartTime = timeStr
+	competitor.SegmentFrom = point
+	competitor.SegmentPosition = position
+}
+
 func (c *Controller) finishCompetitor(competitorID int, timeStr string) {
 	if competitor, exists := c.Competitors[competitorID]; exists {
-		competitor.Status = "Finished"
+		// Дисквалификация жюри до финиша сохраняется; при восстановлении участник будет считаться финишировавшим
+		if competitor.Status == model.StatusDisqualified {
+			competitor.Disqualification.PreviousStatus = model.StatusFinished
+		} else {
+			competitor.Status = model.StatusFinished
+		}
 		competitor.EndTime = timeStr
+		c.finishCount++
+		competitor.FinishOrder = c.finishCount
+		competitor.SkiTime = durationBetween(competitor.ActualStartTime, timeStr) - competitor.TotalRangeTime() - competitor.PenaltyDuration
 
 		evt := event.Event{
 			Time:         timeStr,
 			EventID:      event.Finished,
 			CompetitorID: competitorID,
 		}
-		logEntry := event.FormatLogEntry(evt)
-		c.OutputLog = append(c.OutputLog, fmt.Sprintf("[%s] %s", timeStr, logEntry))
+		c.logEvent(evt, true)
+		c.checkFinish(competitor, timeStr)
+
+		if reason := c.finishViolation(competitor); reason != "" {
+			c.disqualify(competitor, reason, "", timeStr)
+		}
 	}
 }
 
+// finishViolation проверяет на финише, что участник прошёл все огневые рубежи по порядку
+// и все штрафные круги. Рубеж обязателен на каждом круге со стрельбой (HasShooting): firingLines
+// задаёт число рубежей на круге, стреляет участник на одном из них. Возвращает причину дисквалификации или пустую строку.
+func (c *Controller) finishViolation(competitor *model.Competitor) string {
+	if competitor.Status == model.StatusDisqualified {
+		return ""
+	}
+
+	var shootingLaps []int
+	for lap := 1; lap <= c.Config.Laps; lap++ {
+		if c.Config.HasShooting(lap) {
+			shootingLaps = append(shootingLaps, lap)
+		}
+	}
+
+	if len(competitor.RangeVisits) != len(shootingLaps) {
+		return model.ReasonMissedFiringLine
+	}
+	for i, visit := range competitor.RangeVisits {
+		if visit.Lap != shootingLaps[i] {
+			return model.ReasonMissedFiringLine
+		}
+	}
+
+	if competitor.SkippedPenalty > 0 {
+		return model.ReasonMissedPenalty
+	}
+
+	return ""
+}
+
 func (c *Controller) disqualifyCompetitor(competitorID int, evtTime *string) {
 	if competitor, exists := c.Competitors[competitorID]; exists {
-		competitor.Status = "NotStarted"
+		competitor.Status = model.StatusNotStarted
 		if evtTime == nil {
 			plannedStart, _ := model.ParseTime(competitor.PlannedStartTime)
-			startDelta, _ := time.ParseDuration(strings.Replace(c.Config.StartDelta, ":", "h", 1) + "m")
-			disqualificationTime := plannedStart.Add(startDelta)
-			t := model.FormatDuration(time.Since(time.Time{}.Add(disqualificationTime.Sub(time.Time{}))))
+			startDelta, _ := c.Config.StartDeltaDuration()
+			t := plannedStart.Add(startDelta).Format(model.TimeFormat)
 			evtTime = &t
 		}
 
@@ -202,18 +427,78 @@ func (c *Controller) disqualifyCompetitor(competitorID int, evtTime *string) {
 			EventID:      event.Disqualified,
 			CompetitorID: competitorID,
 		}
-		logEntry := event.FormatLogEntry(evt)
-		c.OutputLog = append(c.OutputLog, fmt.Sprintf("[%s] %s", evt.Time, logEntry))
+		c.logEvent(evt, true)
 	}
 }
 
 func (c *Controller) competitorCannotContinue(competitorID int, reason string) {
 	if competitor, exists := c.Competitors[competitorID]; exists {
-		competitor.Status = "NotFinished"
+		competitor.Status = model.StatusNotFinished
 		competitor.CannotContinue = reason
 	}
 }
 
+// durationBetween возвращает длительность между двумя отметками времени или 0, если одна из них не задана
+func durationBetween(from, to string) time.Duration {
+	start, err := model.ParseTime(from)
+	if err != nil {
+		return 0
+	}
+	end, err := model.ParseTime(to)
+	if err != nil {
+		return 0
+	}
+	return end.Sub(start)
+}
+
 func (c *Controller) GenerateReport() string {
 	return report.GenerateFinalReport(c.Competitors, c.Config)
 }
+
+func (c *Controller) GenerateSplitsReport(names ...string) (string, error) {
+	return report.GenerateSplitsReport(c.Competitors, names...)
+}
+
+func (c *Controller) GenerateAnalyticsReport() string {
+	return report.GenerateAnalyticsReport(c.Competitors)
+}
+
+func (c *Controller) GenerateSegmentsReport() string {
+	return report.GenerateSegmentsReport(c.Competitors)
+}
+
+func (c *Controller) GenerateShootingReport() string {
+	return report.GenerateShootingReport(c.Competitors)
+}
+
+func (c *Controller) GenerateStagesReport() string {
+	return report.GenerateStagesReport(c.Competitors, c.Config)
+}
+
+func (c *Controller) GeneratePositionsReport() string {
+	return report.GeneratePositionsReport(c.Competitors, c.Overtakes, c.Config)
+}
+
+func (c *Controller) GeneratePositionsJSON() (string, error) {
+	return report.GeneratePositionsJSON(c.Competitors, c.Overtakes, c.Config)
+}
+
+func (c *Controller) GenerateAnomaliesReport() string {
+	return report.GenerateAnomaliesReport(c.Anomalies)
+}
+
+func (c *Controller) Results() []report.Result {
+	return report.Results(c.Competitors, c.Config)
+}
+
+func (c *Controller) GenerateResultsJSON() (string, error) {
+	return report.GenerateResultsJSON(c.Competitors, c.Config)
+}
+
+func (c *Controller) GenerateResultsHTML(opts report.HTMLOptions) (string, error) {
+	return report.GenerateResultsHTML(c.Competitors, c.Config, opts)
+}
+
+func (c *Controller) GenerateResultSheet(opts report.HTMLOptions) (string, error) {
+	return report.GenerateResultSheet(c.Competitors, c.Config, opts)
+}
@@ -1,10 +1,18 @@
 package race
 
 import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
 	"biathlon/config"
 	"biathlon/event"
 	"biathlon/model"
+	"biathlon/report"
 	"testing"
+	"time"
 )
 
 func TestRegistration(t *testing.T) {
@@ -70,7 +78,16 @@ func TestFullRaceFlow(t *testing.T) {
 		{Time: "10:20:00.000", EventID: event.OnFiringRange, CompetitorID: 1, ExtraParams: "1"},
 		{Time: "10:20:01.000", EventID: event.TargetHit, CompetitorID: 1, ExtraParams: "3"},
 		{Time: "10:20:03.000", EventID: event.LeftFiringRange, CompetitorID: 1},
+		{Time: "10:20:10.000", EventID: event.EnteredPenalty, CompetitorID: 1},
+		{Time: "10:22:00.000", EventID: event.LeftPenalty, CompetitorID: 1},
 		{Time: "10:25:00.000", EventID: event.EndedLap, CompetitorID: 1},
+		{Time: "10:32:00.000", EventID: event.OnFiringRange, CompetitorID: 1, ExtraParams: "1"},
+		{Time: "10:32:02.000", EventID: event.TargetHit, CompetitorID: 1, ExtraParams: "1"},
+		{Time: "10:32:03.000", EventID: event.TargetHit, CompetitorID: 1, ExtraParams: "2"},
+		{Time: "10:32:04.000", EventID: event.TargetHit, CompetitorID: 1, ExtraParams: "3"},
+		{Time: "10:32:05.000", EventID: event.TargetHit, CompetitorID: 1, ExtraParams: "4"},
+		{Time: "10:32:06.000", EventID: event.TargetHit, CompetitorID: 1, ExtraParams: "5"},
+		{Time: "10:32:10.000", EventID: event.LeftFiringRange, CompetitorID: 1},
 		{Time: "10:35:00.000", EventID: event.EndedLap, CompetitorID: 1},
 	}
 
@@ -88,6 +105,17 @@ func TestFullRaceFlow(t *testing.T) {
 	if comp2.Status != "NotStarted" {
 		t.Errorf("Expected NotStarted status for comp2, got %s", comp.Status)
 	}
+	// Проверка точек хронометража
+	if len(comp.Splits) != 7 || comp.Splits[1].Name() != "shooting 1" || comp.Splits[1].Elapsed.String() != "10m3s" {
+		t.Errorf("Unexpected splits: %+v", comp.Splits)
+	}
+	// Проверка времени на рубеже и чистого ходового времени
+	if visit := comp.RangeVisits[0]; visit.RangeTime.String() != "3s" || visit.Hits != 1 {
+		t.Errorf("Unexpected range visit: %+v", visit)
+	}
+	if comp.SkiTime.String() != "22m56s" {
+		t.Errorf("Expected ski time 22m56s, got %s", comp.SkiTime)
+	}
 	// Проверка скорости первого круга
 	if comp.LapTimes[0].Speed < 2.0 {
 		t.Error("Invalid lap speed calculation")
@@ -138,3 +166,475 @@ func TestDisqualification(t *testing.T) {
 		t.Error("Competitor should be disqualified")
 	}
 }
+
+func TestJSONLog(t *testing.T) {
+	cfg := &config.Config{Laps: 1, LapLen: 1000}
+	ctrl := NewController(cfg)
+
+	events := []event.Event{
+		{Time: "10:00:00.000", EventID: event.Registered, CompetitorID: 1},
+		{Time: "10:01:00.000", EventID: event.StartTimeSet, CompetitorID: 1, ExtraParams: "10:10:00.000"},
+		{Time: "10:10:01.000", EventID: event.Started, CompetitorID: 1},
+		{Time: "10:20:00.000", EventID: event.EndedLap, CompetitorID: 1},
+	}
+
+	textLog, err := ctrl.ProcessEvents(events)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	jsonLog, err := ctrl.JSONLog()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(jsonLog), "\n")
+	if len(lines) != len(strings.Split(textLog, "\n")) {
+		t.Fatalf("JSON log has %d entries, text log has %d", len(lines), len(strings.Split(textLog, "\n")))
+	}
+
+	var start, finish LogEntry
+	json.Unmarshal([]byte(lines[1]), &start)
+	json.Unmarshal([]byte(lines[4]), &finish)
+
+	if payload, ok := start.Payload.(map[string]any); !ok || payload["startTime"] != "10:10:00.000" {
+		t.Errorf("Unexpected start time payload: %v", start.Payload)
+	}
+	if finish.EventID != event.Finished || !finish.Generated || finish.Status != "Finished" {
+		t.Errorf("Unexpected finish entry: %+v", finish)
+	}
+}
+
+func TestCourseSegments(t *testing.T) {
+	cfg := &config.Config{
+		Laps:        1,
+		LapLen:      3000,
+		PenaltyLen:  150,
+		FiringLines: 1,
+		Course:      &config.Course{RangeEntrance: 2000, RangeLength: 50, PenaltyEntrance: 2100},
+	}
+	ctrl := NewController(cfg)
+
+	events := []event.Event{
+		{Time: "10:00:00.000", EventID: event.Registered, CompetitorID: 1},
+		{Time: "10:01:00.000", EventID: event.StartTimeSet, CompetitorID: 1, ExtraParams: "10:10:00.000"},
+		{Time: "10:10:00.000", EventID: event.Started, CompetitorID: 1},
+		{Time: "10:17:00.000", EventID: event.OnFiringRange, CompetitorID: 1, ExtraParams: "1"},
+		{Time: "10:17:40.000", EventID: event.LeftFiringRange, CompetitorID: 1},
+		{Time: "10:18:00.000", EventID: event.EnteredPenalty, CompetitorID: 1},
+		{Time: "10:21:00.000", EventID: event.LeftPenalty, CompetitorID: 1},
+		{Time: "10:24:00.000", EventID: event.EndedLap, CompetitorID: 1},
+	}
+	ctrl.ProcessEvents(events)
+
+	segments := ctrl.Competitors[1].Segments
+	if len(segments) != 3 {
+		t.Fatalf("Expected 3 segments, got %+v", segments)
+	}
+
+	want := []struct {
+		name     string
+		distance int
+		speed    float64
+	}{
+		{"start-range", 2000, 2000.0 / 420},
+		{"range-penalty", 50, 50.0 / 20},
+		{"penalty-finish", 900, 900.0 / 180},
+	}
+	for i, w := range want {
+		if segments[i].Name() != w.name || segments[i].Distance != w.distance || segments[i].Speed != w.speed {
+			t.Errorf("Segment %d = %+v, want %s %dm %.3f", i, segments[i], w.name, w.distance, w.speed)
+		}
+	}
+}
+
+func TestVariableLapLengths(t *testing.T) {
+	cfg := &config.Config{
+		Laps:    2,
+		LapList: []config.Lap{{Length: 3000}, {Length: 1200}},
+	}
+	ctrl := NewController(cfg)
+
+	events := []event.Event{
+		{Time: "10:00:00.000", EventID: event.Registered, CompetitorID: 1},
+		{Time: "10:01:00.000", EventID: event.StartTimeSet, CompetitorID: 1, ExtraParams: "10:10:00.000"},
+		{Time: "10:10:00.000", EventID: event.Started, CompetitorID: 1},
+		{Time: "10:20:00.000", EventID: event.EndedLap, CompetitorID: 1},
+		{Time: "10:25:00.000", EventID: event.EndedLap, CompetitorID: 1},
+	}
+	ctrl.ProcessEvents(events)
+
+	laps := ctrl.Competitors[1].LapTimes
+	if laps[0].Distance != 3000 || laps[0].Speed != 5 || laps[1].Distance != 1200 || laps[1].Speed != 4 {
+		t.Errorf("Unexpected lap info: %+v", laps)
+	}
+}
+
+func TestDuplicateAndInvalidTargets(t *testing.T) {
+	cfg := &config.Config{Laps: 1, PenaltyLen: 150}
+	ctrl := NewController(cfg)
+
+	events := []event.Event{
+		{Time: "10:00:00.000", EventID: event.Registered, CompetitorID: 1},
+		{Time: "10:01:00.000", EventID: event.StartTimeSet, CompetitorID: 1, ExtraParams: "10:10:00.000"},
+		{Time: "10:10:00.000", EventID: event.Started, CompetitorID: 1},
+		{Time: "10:17:00.000", EventID: event.OnFiringRange, CompetitorID: 1, ExtraParams: "1"},
+		{Time: "10:17:01.000", EventID: event.TargetHit, CompetitorID: 1, ExtraParams: "1"},
+		{Time: "10:17:02.000", EventID: event.TargetHit, CompetitorID: 1, ExtraParams: "1"},
+		{Time: "10:17:03.000", EventID: event.TargetHit, CompetitorID: 1, ExtraParams: "6"},
+		{Time: "10:17:04.000", EventID: event.TargetHit, CompetitorID: 1, ExtraParams: "2"},
+		{Time: "10:17:05.000", EventID: event.LeftFiringRange, CompetitorID: 1},
+	}
+
+	problems := 0
+	for _, evt := range events {
+		problems += len(ctrl.Check(evt))
+		ctrl.ProcessEvent(evt)
+	}
+
+	comp := ctrl.Competitors[1]
+	if comp.HitsCount != 2 || comp.RangeVisits[0].Hits != 2 || comp.PendingPenalty != 3 {
+		t.Errorf("Expected 2 hits and 3 pending penalty loops, got %d hits, %d pending", comp.HitsCount, comp.PendingPenalty)
+	}
+	if problems != 2 {
+		t.Errorf("Expected duplicate and invalid target problems, got %d", problems)
+	}
+}
+
+func TestAnomalies(t *testing.T) {
+	cfg := &config.Config{
+		Laps:        1,
+		LapLen:      3000,
+		PenaltyLen:  150,
+		FiringLines: 1,
+		Limits:      &config.Limits{MaxSpeed: 10, MinRangeTime: "00:00:10"},
+	}
+	ctrl := NewController(cfg)
+
+	events := []event.Event{
+		{Time: "10:00:00.000", EventID: event.Registered, CompetitorID: 1},
+		{Time: "10:01:00.000", EventID: event.StartTimeSet, CompetitorID: 1, ExtraParams: "10:10:00.000"},
+		{Time: "10:10:00.000", EventID: event.Started, CompetitorID: 1},
+		{Time: "10:11:00.000", EventID: event.OnFiringRange, CompetitorID: 1, ExtraParams: "1"},
+		{Time: "10:11:05.000", EventID: event.LeftFiringRange, CompetitorID: 1},
+		{Time: "10:11:10.000", EventID: event.EnteredPenalty, CompetitorID: 1},
+		{Time: "10:11:20.000", EventID: event.LeftPenalty, CompetitorID: 1},
+		{Time: "10:12:00.000", EventID: event.EndedLap, CompetitorID: 1},
+	}
+	log, _ := ctrl.ProcessEvents(events)
+
+	kinds := make([]string, len(ctrl.Anomalies))
+	for i, anomaly := range ctrl.Anomalies {
+		kinds[i] = anomaly.Kind
+	}
+	want := []string{model.AnomalyRangeTime, model.AnomalyPenaltySpeed, model.AnomalyLapSpeed, model.AnomalyFinishTime}
+	if strings.Join(kinds, ",") != strings.Join(want, ",") {
+		t.Errorf("Anomalies = %v, want %v", kinds, want)
+	}
+	speed := fmt.Sprintf("%.3f m/s", ctrl.Competitors[1].PenaltySpeed)
+	if !strings.Contains(ctrl.Anomalies[1].Message, speed) {
+		t.Errorf("Penalty anomaly %q must report the speed from the report (%s)", ctrl.Anomalies[1].Message, speed)
+	}
+	if !strings.Contains(log, "[10:12:00.000] Warning for competitor(1): lap speed") {
+		t.Errorf("Expected lap speed warning in log:\n%s", log)
+	}
+}
+
+func TestFinishViolations(t *testing.T) {
+	cfg := &config.Config{Laps: 2, LapLen: 3000, PenaltyLen: 150, FiringLines: 2}
+
+	tests := []struct {
+		name   string
+		events []event.Event
+		status string
+		reason string
+	}{
+		{
+			name: "missed firing line",
+			events: []event.Event{
+				{Time: "10:17:00.000", EventID: event.OnFiringRange, CompetitorID: 1, ExtraParams: "1"},
+				{Time: "10:17:30.000", EventID: event.LeftFiringRange, CompetitorID: 1},
+				{Time: "10:17:40.000", EventID: event.EnteredPenalty, CompetitorID: 1},
+				{Time: "10:21:00.000", EventID: event.LeftPenalty, CompetitorID: 1},
+				{Time: "10:22:00.000", EventID: event.EndedLap, CompetitorID: 1},
+				{Time: "10:34:00.000", EventID: event.EndedLap, CompetitorID: 1},
+			},
+			status: "Disqualified",
+			reason: model.ReasonMissedFiringLine,
+		},
+		{
+			name: "missed penalty",
+			events: []event.Event{
+				{Time: "10:17:00.000", EventID: event.OnFiringRange, CompetitorID: 1, ExtraParams: "1"},
+				{Time: "10:17:30.000", EventID: event.LeftFiringRange, CompetitorID: 1},
+				{Time: "10:22:00.000", EventID: event.EndedLap, CompetitorID: 1},
+				{Time: "10:29:00.000", EventID: event.OnFiringRange, CompetitorID: 1, ExtraParams: "2"},
+				{Time: "10:29:30.000", EventID: event.LeftFiringRange, CompetitorID: 1},
+				{Time: "10:29:40.000", EventID: event.EnteredPenalty, CompetitorID: 1},
+				{Time: "10:33:00.000", EventID: event.LeftPenalty, CompetitorID: 1},
+				{Time: "10:36:00.000", EventID: event.EndedLap, CompetitorID: 1},
+			},
+			status: "Disqualified",
+			reason: model.ReasonMissedPenalty,
+		},
+	}
+
+	for _, tt := range tests {
+		ctrl := NewController(cfg)
+		events := append([]event.Event{
+			{Time: "10:00:00.000", EventID: event.Registered, CompetitorID: 1},
+			{Time: "10:01:00.000", EventID: event.StartTimeSet, CompetitorID: 1, ExtraParams: "10:10:00.000"},
+			{Time: "10:10:00.000", EventID: event.Started, CompetitorID: 1},
+		}, tt.events...)
+		log, _ := ctrl.ProcessEvents(events)
+
+		comp := ctrl.Competitors[1]
+		if comp.Status != tt.status || comp.Disqualification == nil || comp.Disqualification.Reason != tt.reason {
+			t.Errorf("%s: got %s/%+v, want %s/%s", tt.name, comp.Status, comp.Disqualification, tt.status, tt.reason)
+		}
+		if !strings.HasSuffix(log, "The competitor(1) is disqualified") {
+			t.Errorf("%s: expected disqualification in log:\n%s", tt.name, log)
+		}
+	}
+}
+
+// Пример конфигурации из README: два круга, по одному рубежу на круге
+func TestReadmeConfigCleanRace(t *testing.T) {
+	cfg := &config.Config{Laps: 2, LapLen: 3651, PenaltyLen: 50, FiringLines: 1, Start: "09:30:00", StartDelta: "00:00:30"}
+
+	shooting := func(arrival, exit string) []event.Event {
+		events := []event.Event{{Time: arrival, EventID: event.OnFiringRange, CompetitorID: 1, ExtraParams: "1"}}
+		for target := 1; target <= 5; target++ {
+			events = append(events, event.Event{Time: exit, EventID: event.TargetHit, CompetitorID: 1, ExtraParams: strconv.Itoa(target)})
+		}
+		return append(events, event.Event{Time: exit, EventID: event.LeftFiringRange, CompetitorID: 1})
+	}
+	start := []event.Event{
+		{Time: "09:05:59.867", EventID: event.Registered, CompetitorID: 1},
+		{Time: "09:15:00.841", EventID: event.StartTimeSet, CompetitorID: 1, ExtraParams: "09:30:00.000"},
+		{Time: "09:29:45.734", EventID: event.OnStartLine, CompetitorID: 1},
+		{Time: "09:30:01.005", EventID: event.Started, CompetitorID: 1},
+	}
+
+	var events []event.Event
+	events = append(events, start...)
+	events = append(events, shooting("09:45:00.000", "09:45:30.000")...)
+	events = append(events, event.Event{Time: "09:50:00.000", EventID: event.EndedLap, CompetitorID: 1})
+	events = append(events, shooting("10:05:00.000", "10:05:30.000")...)
+	events = append(events, event.Event{Time: "10:10:00.000", EventID: event.EndedLap, CompetitorID: 1})
+
+	ctrl := NewController(cfg)
+	for _, evt := range events {
+		if problems := ctrl.Check(evt); len(problems) != 0 {
+			t.Errorf("Check(%s) = %v", evt, problems)
+		}
+		ctrl.ProcessEvent(evt)
+	}
+
+	comp := ctrl.Competitors[1]
+	if comp.Status != model.StatusFinished || comp.Disqualification != nil || comp.HitsCount != 10 {
+		t.Errorf("Clean race must finish, got %s/%+v with %d hits", comp.Status, comp.Disqualification, comp.HitsCount)
+	}
+
+	// Пропуск второго рубежа - дисквалификация, и validate сообщает о ней как об ошибке
+	ctrl = NewController(cfg)
+	for _, evt := range events[:len(events)-8] {
+		ctrl.ProcessEvent(evt)
+	}
+	finish := event.Event{Time: "10:10:00.000", EventID: event.EndedLap, CompetitorID: 1}
+	if problems := ctrl.Check(finish); len(problems) != 1 || problems[0].Severity != SeverityError {
+		t.Errorf("Expected missed firing line error, got %v", problems)
+	}
+	ctrl.ProcessEvent(finish)
+	if comp := ctrl.Competitors[1]; comp.Disqualification == nil || comp.Disqualification.Reason != model.ReasonMissedFiringLine {
+		t.Errorf("Expected %s disqualification, got %s/%+v", model.ReasonMissedFiringLine, comp.Status, comp.Disqualification)
+	}
+}
+
+func TestJuryDecisions(t *testing.T) {
+	cfg := &config.Config{Laps: 1, LapLen: 3000, PenaltyLen: 150, FiringLines: 1}
+	ctrl := NewController(cfg)
+
+	events := []event.Event{
+		{Time: "10:00:00.000", EventID: event.Registered, CompetitorID: 1},
+		{Time: "10:01:00.000", EventID: event.StartTimeSet, CompetitorID: 1, ExtraParams: "10:10:00.000"},
+		{Time: "10:10:00.000", EventID: event.Started, CompetitorID: 1},
+		{Time: "10:17:00.000", EventID: event.OnFiringRange, CompetitorID: 1, ExtraParams: "1"},
+		{Time: "10:17:30.000", EventID: event.LeftFiringRange, CompetitorID: 1},
+		{Time: "10:22:00.000", EventID: event.EndedLap, CompetitorID: 1},
+		{Time: "10:30:00.000", EventID: event.JuryDecision, CompetitorID: 1, ExtraParams: "DSQ IllegalEquipment Ski wax test failed"},
+	}
+	ctrl.ProcessEvents(events)
+
+	comp := ctrl.Competitors[1]
+	if comp.Status != model.StatusDisqualified || comp.Disqualification == nil {
+		t.Fatalf("Expected disqualification, got %s/%+v", comp.Status, comp.Disqualification)
+	}
+	// Первая дисквалификация (пропуск штрафных кругов) сохраняется, повторное решение жюри лишь записывается
+	if comp.Disqualification.Reason != model.ReasonMissedPenalty {
+		t.Errorf("Reason = %s, want %s", comp.Disqualification.Reason, model.ReasonMissedPenalty)
+	}
+
+	ctrl.ProcessEvent(event.Event{Time: "10:35:00.000", EventID: event.JuryDecision, CompetitorID: 1, ExtraParams: "REINSTATE Sensor failure"})
+	if comp.Status != model.StatusFinished || comp.Disqualification != nil {
+		t.Errorf("Expected reinstatement to Finished, got %s/%+v", comp.Status, comp.Disqualification)
+	}
+
+	ctrl.ProcessEvent(event.Event{Time: "10:40:00.000", EventID: event.JuryDecision, CompetitorID: 1, ExtraParams: "PENALTY +00:01:00 WrongLane"})
+	if len(comp.Decisions) != 3 {
+		t.Fatalf("Expected 3 recorded decisions, got %d", len(comp.Decisions))
+	}
+	if last := comp.Decisions[2]; last.Action != event.JuryPenalty || last.Penalty != time.Minute || last.Reason != "WrongLane" {
+		t.Errorf("Unexpected penalty decision: %+v", last)
+	}
+	if comp.TotalAdjustment() != time.Minute || comp.TimeAdjustments[0].Reason != "WrongLane" {
+		t.Errorf("Unexpected time adjustments: %+v", comp.TimeAdjustments)
+	}
+
+	problems := ctrl.Check(event.Event{Time: "10:45:00.000", EventID: event.JuryDecision, CompetitorID: 1, ExtraParams: "REINSTATE"})
+	if len(problems) != 1 || problems[0].Severity != SeverityError {
+		t.Errorf("Expected error for reinstating a competitor who is not disqualified, got %v", problems)
+	}
+}
+
+func TestJuryDisqualificationBeforeFinish(t *testing.T) {
+	cfg := &config.Config{Laps: 1, LapLen: 3000, PenaltyLen: 150, FiringLines: 1, Start: "10:00:00.000"}
+	ctrl := NewController(cfg)
+
+	events := []event.Event{
+		{Time: "10:00:00.000", EventID: event.Registered, CompetitorID: 1},
+		{Time: "10:01:00.000", EventID: event.StartTimeSet, CompetitorID: 1, ExtraParams: "10:10:00.000"},
+		{Time: "10:10:00.000", EventID: event.Started, CompetitorID: 1},
+		{Time: "10:17:00.000", EventID: event.OnFiringRange, CompetitorID: 1, ExtraParams: "1"},
+		{Time: "10:17:10.000", EventID: event.TargetHit, CompetitorID: 1, ExtraParams: "1"},
+		{Time: "10:17:11.000", EventID: event.TargetHit, CompetitorID: 1, ExtraParams: "2"},
+		{Time: "10:17:12.000", EventID: event.TargetHit, CompetitorID: 1, ExtraParams: "3"},
+		{Time: "10:17:13.000", EventID: event.TargetHit, CompetitorID: 1, ExtraParams: "4"},
+		{Time: "10:17:14.000", EventID: event.TargetHit, CompetitorID: 1, ExtraParams: "5"},
+		{Time: "10:17:30.000", EventID: event.LeftFiringRange, CompetitorID: 1},
+		{Time: "10:18:00.000", EventID: event.JuryDecision, CompetitorID: 1, ExtraParams: "DSQ UnsportsmanlikeConduct"},
+		{Time: "10:22:00.000", EventID: event.EndedLap, CompetitorID: 1},
+	}
+	ctrl.ProcessEvents(events)
+
+	comp := ctrl.Competitors[1]
+	if comp.Status != model.StatusDisqualified || comp.Disqualification.Reason != "UnsportsmanlikeConduct" {
+		t.Fatalf("Expected jury disqualification to survive the finish, got %s/%+v", comp.Status, comp.Disqualification)
+	}
+	if results := ctrl.Results(); results[0].Rank != 0 || results[0].Status != model.StatusDisqualified {
+		t.Errorf("Disqualified competitor must not be ranked: %+v", results[0])
+	}
+
+	ctrl.ProcessEvent(event.Event{Time: "10:30:00.000", EventID: event.JuryDecision, CompetitorID: 1, ExtraParams: "REINSTATE"})
+	if comp.Status != model.StatusFinished {
+		t.Errorf("Expected reinstatement to Finished, got %s", comp.Status)
+	}
+}
+
+func TestMassStart(t *testing.T) {
+	cfg := &config.Config{Laps: 1, LapLen: 3000, PenaltyLen: 150, FiringLines: 1, Start: "10:00:00", Mode: config.ModeMassStart, RangeLanes: 2}
+	ctrl := NewController(cfg)
+
+	var events []event.Event
+	for id := 1; id <= 3; id++ {
+		events = append(events,
+			event.Event{Time: "09:30:00.000", EventID: event.Registered, CompetitorID: id},
+			event.Event{Time: "09:40:00.000", EventID: event.StartTimeSet, CompetitorID: id, ExtraParams: "09:45:00.000"},
+		)
+	}
+	for id := 1; id <= 3; id++ {
+		events = append(events, event.Event{Time: "10:00:00.000", EventID: event.Started, CompetitorID: id})
+	}
+	// Первый рубеж: установка по номеру с учётом числа установок (3 -> 1); участник 2 встал не на свою
+	for _, evt := range []struct{ id, lane int }{{3, 1}, {1, 1}, {2, 1}} {
+		events = append(events, event.Event{Time: "10:10:00.000", EventID: event.OnFiringRange, CompetitorID: evt.id, ExtraParams: strconv.Itoa(evt.lane)})
+		for target := 1; target <= 5; target++ {
+			events = append(events, event.Event{Time: "10:10:10.000", EventID: event.TargetHit, CompetitorID: evt.id, ExtraParams: strconv.Itoa(target)})
+		}
+		events = append(events, event.Event{Time: "10:10:30.000", EventID: event.LeftFiringRange, CompetitorID: evt.id})
+	}
+	events = append(events,
+		event.Event{Time: "10:20:00.000", EventID: event.EndedLap, CompetitorID: 2},
+		event.Event{Time: "10:20:00.000", EventID: event.EndedLap, CompetitorID: 3},
+		event.Event{Time: "10:20:00.000", EventID: event.EndedLap, CompetitorID: 1},
+	)
+	ctrl.ProcessEvents(events)
+
+	if got := ctrl.Competitors[1].PlannedStartTime; got != "10:00:00.000" {
+		t.Errorf("PlannedStartTime = %s, want mass start time", got)
+	}
+	if len(ctrl.Anomalies) != 1 || ctrl.Anomalies[0].CompetitorID != 2 || ctrl.Anomalies[0].Kind != model.AnomalyRangeLane {
+		t.Errorf("Expected one lane anomaly for competitor 2, got %v", ctrl.Anomalies)
+	}
+
+	problems := ctrl.Check(event.Event{Time: "10:21:00.000", EventID: event.PhotoFinish, CompetitorID: 1, ExtraParams: "2"})
+	if len(problems) != 0 {
+		t.Errorf("Unexpected photo finish problems: %v", problems)
+	}
+	ctrl.ProcessEvent(event.Event{Time: "10:21:00.000", EventID: event.PhotoFinish, CompetitorID: 1, ExtraParams: "2"})
+
+	var order []int
+	for _, result := range ctrl.Results() {
+		order = append(order, result.CompetitorID)
+		if result.Rank != len(order) {
+			t.Errorf("Competitor %d rank = %d, mass start ranks must not be shared", result.CompetitorID, result.Rank)
+		}
+	}
+	// Фотофиниш ставит 1 перед 2, не меняя местами 2 и 3
+	if !reflect.DeepEqual(order, []int{1, 2, 3}) {
+		t.Errorf("Finish order = %v, want [1 2 3]", order)
+	}
+}
+
+func TestPursuitPositions(t *testing.T) {
+	cfg := &config.Config{Laps: 1, LapLen: 3000, PenaltyLen: 150, FiringLines: 1, Start: "10:00:00", StartDelta: "00:00:30", Mode: config.ModePursuit}
+	ctrl := NewController(cfg)
+
+	events := []event.Event{
+		{Time: "09:30:00.000", EventID: event.Registered, CompetitorID: 1},
+		{Time: "09:30:00.000", EventID: event.Registered, CompetitorID: 2},
+		{Time: "09:40:00.000", EventID: event.StartTimeSet, CompetitorID: 1, ExtraParams: "10:00:00.000"},
+		{Time: "09:40:00.000", EventID: event.StartTimeSet, CompetitorID: 2, ExtraParams: "10:00:30.000"},
+		{Time: "10:00:00.000", EventID: event.Started, CompetitorID: 1},
+		{Time: "10:00:30.000", EventID: event.Started, CompetitorID: 2},
+		{Time: "10:10:00.000", EventID: event.OnFiringRange, CompetitorID: 1, ExtraParams: "1"},
+		{Time: "10:10:05.000", EventID: event.OnFiringRange, CompetitorID: 2, ExtraParams: "2"},
+	}
+	for target := 1; target <= 5; target++ {
+		events = append(events,
+			event.Event{Time: "10:10:10.000", EventID: event.TargetHit, CompetitorID: 1, ExtraParams: strconv.Itoa(target)},
+			event.Event{Time: "10:10:10.000", EventID: event.TargetHit, CompetitorID: 2, ExtraParams: strconv.Itoa(target)},
+		)
+	}
+	events = append(events,
+		event.Event{Time: "10:10:30.000", EventID: event.LeftFiringRange, CompetitorID: 2},
+		event.Event{Time: "10:10:40.000", EventID: event.LeftFiringRange, CompetitorID: 1},
+		event.Event{Time: "10:20:00.000", EventID: event.EndedLap, CompetitorID: 2},
+		event.Event{Time: "10:20:01.000", EventID: event.EndedLap, CompetitorID: 1},
+	)
+	ctrl.ProcessEvents(events)
+
+	want := []model.Overtake{{Time: "10:10:30.000", Point: "shooting 1", CompetitorID: 2, OvertakenID: 1}}
+	if !reflect.DeepEqual(ctrl.Overtakes, want) {
+		t.Errorf("Overtakes = %+v, want %+v", ctrl.Overtakes, want)
+	}
+
+	series := report.Positions(ctrl.Competitors, ctrl.Overtakes, ctrl.Config)
+	var history []string
+	for _, h := range series.Series {
+		positions := make([]string, len(h.Positions))
+		for i, p := range h.Positions {
+			positions[i] = strconv.Itoa(*p)
+		}
+		history = append(history, fmt.Sprintf("%d: %s", h.CompetitorID, strings.Join(positions, " ")))
+	}
+	if got := strings.Join(history, ", "); got != "2: 2 1 1, 1: 1 2 2" {
+		t.Errorf("Position history = %s", got)
+	}
+
+	// В преследовании итоговое время считается от общего старта, поэтому места совпадают с порядком финиша
+	results := ctrl.Results()
+	if results[0].CompetitorID != 2 || results[0].TotalTime != "00:20:00.000" || results[1].Rank != 2 {
+		t.Errorf("Unexpected pursuit results: %+v", results)
+	}
+}
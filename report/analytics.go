@@ -0,0 +1,110 @@
+package report
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"biathlon/model"
+)
+
+type AnalyticsEntry struct {
+	Rank       int
+	Competitor *model.Competitor
+	Average    time.Duration
+	Best       time.Duration
+	Count      int
+}
+
+// RangeTimeRanking ранжирует участников по среднему времени пребывания на огневом рубеже
+func RangeTimeRanking(competitors map[int]*model.Competitor) []AnalyticsEntry {
+	return rankVisits(competitors, func(visit model.RangeVisit) (time.Duration, bool) {
+		return visit.RangeTime, visit.ExitTime != ""
+	})
+}
+
+// ShootingTimeRanking ранжирует участников по среднему времени стрельбы (от первого до последнего попадания)
+func ShootingTimeRanking(competitors map[int]*model.Competitor) []AnalyticsEntry {
+	return rankVisits(competitors, func(visit model.RangeVisit) (time.Duration, bool) {
+		return visit.ShootingTime, visit.ExitTime != "" && visit.Hits > 1
+	})
+}
+
+// SkiTimeRanking ранжирует финишировавших участников по чистому ходовому времени без рубежей и штрафных кругов
+func SkiTimeRanking(competitors map[int]*model.Competitor) []AnalyticsEntry {
+	var entries []AnalyticsEntry
+	for _, competitor := range competitors {
+		if competitor.Status == model.StatusFinished {
+			entries = append(entries, AnalyticsEntry{Competitor: competitor, Average: competitor.SkiTime, Best: competitor.SkiTime, Count: 1})
+		}
+	}
+	return rankEntries(entries)
+}
+
+func rankVisits(competitors map[int]*model.Competitor, value func(model.RangeVisit) (time.Duration, bool)) []AnalyticsEntry {
+	var entries []AnalyticsEntry
+	for _, competitor := range competitors {
+		entry := AnalyticsEntry{Competitor: competitor}
+		var total time.Duration
+		for _, visit := range competitor.RangeVisits {
+			d, ok := value(visit)
+			if !ok {
+				continue
+			}
+			if entry.Count == 0 || d < entry.Best {
+				entry.Best = d
+			}
+			total += d
+			entry.Count++
+		}
+
+		if entry.Count > 0 {
+			entry.Average = total / time.Duration(entry.Count)
+			entries = append(entries, entry)
+		}
+	}
+	return rankEntries(entries)
+}
+
+func rankEntries(entries []AnalyticsEntry) []AnalyticsEntry {
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Average != entries[j].Average {
+			return entries[i].Average < entries[j].Average
+		}
+		return entries[i].Competitor.ID < entries[j].Competitor.ID
+	})
+
+	for i := range entries {
+		entries[i].Rank = i + 1
+	}
+	return entries
+}
+
+func GenerateAnalyticsReport(competitors map[int]*model.Competitor) string {
+	var report strings.Builder
+
+	sections := []struct {
+		title   string
+		entries []AnalyticsEntry
+	}{
+		{"range time", RangeTimeRanking(competitors)},
+		{"shooting time", ShootingTimeRanking(competitors)},
+	}
+
+	for _, section := range sections {
+		report.WriteString(fmt.Sprintf("[%s]\n", section.title))
+		for _, entry := range section.entries {
+			report.WriteString(fmt.Sprintf("%d %d avg %s best %s visits %d\n", entry.Rank, entry.Competitor.ID,
+				model.FormatDuration(entry.Average), model.FormatDuration(entry.Best), entry.Count))
+		}
+		report.WriteString("\n")
+	}
+
+	report.WriteString("[ski time]\n")
+	for _, entry := range SkiTimeRanking(competitors) {
+		report.WriteString(fmt.Sprintf("%d %d %s\n", entry.Rank, entry.Competitor.ID, model.FormatDuration(entry.Average)))
+	}
+
+	return report.String()
+}
@@ -0,0 +1,16 @@
+package report
+
+import (
+	"fmt"
+	"strings"
+
+	"biathlon/model"
+)
+
+func GenerateAnomaliesReport(anomalies []model.Anomaly) string {
+	var report strings.Builder
+	for _, anomaly := range anomalies {
+		report.WriteString(fmt.Sprintf("[%s] %d %s: %s\n", anomaly.Time, anomaly.CompetitorID, anomaly.Kind, anomaly.Message))
+	}
+	return report.String()
+}
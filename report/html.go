@@ -0,0 +1,175 @@
+package report
+
+import (
+	_ "embed"
+	"fmt"
+	"html/template"
+	"strings"
+	"time"
+
+	"biathlon/config"
+	"biathlon/model"
+	"biathlon/roster"
+)
+
+//go:embed results.html
+var resultsHTML string
+
+var resultsTemplate = template.Must(template.New("results").Parse(resultsHTML))
+
+type HTMLOptions struct {
+	// Заголовок страницы; по умолчанию "Results"
+	Title string
+	// Необязательный заявочный список: с ним в таблицах выводятся имена и страны
+	Roster roster.Roster
+}
+
+type htmlPage struct {
+	Title        string
+	Subtitle     string
+	ShowNames    bool
+	LapHeaders   []string
+	StageHeaders []string
+	Ranked       []htmlRow
+	Disqualified []htmlRow
+	NotFinished  []htmlRow
+	NotStarted   []htmlRow
+}
+
+type htmlRow struct {
+	Result
+	Name        string
+	Nation      string
+	TimeSort    int64
+	Gap         string
+	GapSort     int64
+	PenaltySort string
+	Laps        []htmlCell
+	Stages      []htmlCell
+	Jury        string
+}
+
+type htmlCell struct {
+	Text string
+	Sort string
+	Miss bool
+}
+
+// GenerateResultsHTML возвращает самодостаточную страницу итогового протокола: таблицу мест с разбивкой
+// по кругам и рубежам, списки DSQ/DNF/DNS с причинами, сортировку по столбцам и стили для печати
+func GenerateResultsHTML(competitors map[int]*model.Competitor, cfg *config.Config, opts HTMLOptions) (string, error) {
+	page := newHTMLPage(competitors, cfg, opts)
+	if page.Title == "" {
+		page.Title = "Results"
+	}
+
+	var b strings.Builder
+	if err := resultsTemplate.Execute(&b, page); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// newHTMLPage собирает строки протокола, общие для страницы результатов и официального протокола
+func newHTMLPage(competitors map[int]*model.Competitor, cfg *config.Config, opts HTMLOptions) htmlPage {
+	page := htmlPage{Title: opts.Title, Subtitle: raceSummary(cfg), ShowNames: len(opts.Roster) > 0}
+
+	for lap := 1; lap <= cfg.Laps; lap++ {
+		page.LapHeaders = append(page.LapHeaders, fmt.Sprintf("Lap %d", lap))
+	}
+	order := cfg.ShootingOrder()
+	for lap, stage := 1, 0; lap <= cfg.Laps; lap++ {
+		if !cfg.HasShooting(lap) {
+			continue
+		}
+		header := fmt.Sprintf("Stage %d", stage+1)
+		if stage < len(order) {
+			header += " " + order[stage]
+		}
+		page.StageHeaders = append(page.StageHeaders, header)
+		stage++
+	}
+
+	var leader time.Duration
+	for _, result := range Results(competitors, cfg) {
+		row := htmlRow{Result: result}
+		if athlete, ok := opts.Roster[result.CompetitorID]; ok {
+			row.Name, row.Nation = athlete.Name, athlete.Nation
+		}
+
+		switch {
+		case result.Rank > 0:
+			if len(page.Ranked) == 0 {
+				leader = result.Duration()
+			}
+			row.TimeSort = result.Duration().Milliseconds()
+			row.GapSort = (result.Duration() - leader).Milliseconds()
+			if row.GapSort > 0 {
+				row.Gap = "+" + model.FormatDuration(result.Duration()-leader)
+			}
+			row.PenaltySort = clockMillis(result.PenaltyTime)
+			row.Laps = lapCells(result, cfg.Laps)
+			row.Stages = stageCells(result, len(page.StageHeaders))
+			row.Jury = juryNotes(result)
+			page.Ranked = append(page.Ranked, row)
+		case result.Status == model.StatusDisqualified:
+			page.Disqualified = append(page.Disqualified, row)
+		case result.Status == model.StatusNotFinished || result.Status == model.StatusRunning:
+			page.NotFinished = append(page.NotFinished, row)
+		default:
+			page.NotStarted = append(page.NotStarted, row)
+		}
+	}
+
+	return page
+}
+
+func raceSummary(cfg *config.Config) string {
+	mode := "Individual start"
+	switch cfg.Mode {
+	case config.ModeMassStart:
+		mode = "Mass start"
+	case config.ModePursuit:
+		mode = "Pursuit"
+	}
+	laps := "laps"
+	if cfg.Laps == 1 {
+		laps = "lap"
+	}
+	return fmt.Sprintf("%s, %d %s, %d m, start %s", mode, cfg.Laps, laps, cfg.TotalDistance(), cfg.Start)
+}
+
+func lapCells(result Result, laps int) []htmlCell {
+	cells := make([]htmlCell, laps)
+	for i := 0; i < laps && i < len(result.Laps); i++ {
+		cells[i] = htmlCell{Text: result.Laps[i].Time, Sort: clockMillis(result.Laps[i].Time)}
+	}
+	return cells
+}
+
+func stageCells(result Result, stages int) []htmlCell {
+	cells := make([]htmlCell, stages)
+	for i := 0; i < stages && i < len(result.Shooting); i++ {
+		hits := result.Shooting[i].Hits
+		cells[i] = htmlCell{Text: fmt.Sprint(hits), Sort: fmt.Sprint(hits), Miss: hits < model.TargetsPerVisit}
+	}
+	return cells
+}
+
+func juryNotes(result Result) string {
+	notes := make([]string, len(result.Adjustments))
+	for i, adjustment := range result.Adjustments {
+		notes[i] = adjustment.Duration + " " + adjustment.Reason
+	}
+	return strings.Join(notes, ", ")
+}
+
+// clockMillis переводит HH:MM:SS.sss в миллисекунды для сортировки; пустая строка остаётся пустой
+func clockMillis(s string) string {
+	t, err := model.ParseTime(s)
+	if err != nil {
+		return ""
+	}
+	midnight := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	return fmt.Sprint(t.Sub(midnight).Milliseconds())
+}
@@ -0,0 +1,119 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"biathlon/config"
+	"biathlon/model"
+)
+
+// PositionSeries - история мест участников по точкам хронометража в виде, удобном для построения графиков
+type PositionSeries struct {
+	Points    []string          `json:"points"`
+	Series    []PositionHistory `json:"series"`
+	Overtakes []OvertakeResult  `json:"overtakes"`
+}
+
+// PositionHistory - места участника на каждой точке из Points; null - точка не пройдена
+type PositionHistory struct {
+	CompetitorID int    `json:"competitorId"`
+	Positions    []*int `json:"positions"`
+}
+
+type OvertakeResult struct {
+	Time         string `json:"time"`
+	Point        string `json:"point"`
+	CompetitorID int    `json:"competitorId"`
+	OvertakenID  int    `json:"overtakenId"`
+}
+
+// Positions возвращает историю мест участников в порядке итогового протокола.
+// Места отслеживаются только в гонках с общим ходом, в остальных история пуста.
+func Positions(competitors map[int]*model.Competitor, overtakes []model.Overtake, cfg *config.Config) PositionSeries {
+	tracked := make(map[string]bool)
+	for _, competitor := range competitors {
+		for _, split := range competitor.Splits {
+			if split.Position > 0 {
+				tracked[split.Name()] = true
+			}
+		}
+	}
+
+	series := PositionSeries{Points: []string{}, Series: []PositionHistory{}, Overtakes: []OvertakeResult{}}
+	index := make(map[string]int)
+	for _, name := range SplitNames(competitors) {
+		if tracked[name] {
+			index[name] = len(series.Points)
+			series.Points = append(series.Points, name)
+		}
+	}
+	if len(series.Points) == 0 {
+		return series
+	}
+
+	for _, competitor := range rankOrder(competitors, cfg) {
+		history := PositionHistory{CompetitorID: competitor.ID, Positions: make([]*int, len(series.Points))}
+		passed := false
+		for _, split := range competitor.Splits {
+			if split.Position > 0 {
+				position := split.Position
+				history.Positions[index[split.Name()]] = &position
+				passed = true
+			}
+		}
+		if passed {
+			series.Series = append(series.Series, history)
+		}
+	}
+
+	for _, overtake := range overtakes {
+		series.Overtakes = append(series.Overtakes, OvertakeResult(overtake))
+	}
+	// Обгоны по фотофинишу добавляются позже остальных
+	sort.SliceStable(series.Overtakes, func(i, j int) bool {
+		return series.Overtakes[i].Time < series.Overtakes[j].Time
+	})
+	return series
+}
+
+// GeneratePositionsReport выводит историю мест каждого участника, например "5 → 3 → 1 → 2"
+// ("-" - точка не пройдена), и список обгонов
+func GeneratePositionsReport(competitors map[int]*model.Competitor, overtakes []model.Overtake, cfg *config.Config) string {
+	series := Positions(competitors, overtakes, cfg)
+	if len(series.Points) == 0 {
+		return ""
+	}
+
+	var report strings.Builder
+	report.WriteString(fmt.Sprintf("[%s]\n", strings.Join(series.Points, " → ")))
+	for _, history := range series.Series {
+		positions := make([]string, len(history.Positions))
+		for i, position := range history.Positions {
+			positions[i] = "-"
+			if position != nil {
+				positions[i] = fmt.Sprint(*position)
+			}
+		}
+		report.WriteString(fmt.Sprintf("%d: %s\n", history.CompetitorID, strings.Join(positions, " → ")))
+	}
+
+	if len(series.Overtakes) > 0 {
+		report.WriteString("\n[overtakes]\n")
+		for _, overtake := range series.Overtakes {
+			report.WriteString(fmt.Sprintf("[%s] %s: %d overtook %d\n", overtake.Time, overtake.Point, overtake.CompetitorID, overtake.OvertakenID))
+		}
+	}
+
+	return report.String()
+}
+
+func GeneratePositionsJSON(competitors map[int]*model.Competitor, overtakes []model.Overtake, cfg *config.Config) (string, error) {
+	data, err := json.MarshalIndent(Positions(competitors, overtakes, cfg), "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(data) + "\n", nil
+}
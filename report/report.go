@@ -12,57 +12,22 @@ import (
 )
 
 func GenerateFinalReport(competitors map[int]*model.Competitor, cfg *config.Config) string {
-	var sortedCompetitors []*model.Competitor
-	for _, competitor := range competitors {
-		sortedCompetitors = append(sortedCompetitors, competitor)
-	}
-
-	sort.Slice(sortedCompetitors, func(i, j int) bool {
-		c1, c2 := sortedCompetitors[i], sortedCompetitors[j]
-
-		if c1.Status != c2.Status {
-			if c1.Status == "Finished" && c2.Status != "Finished" {
-				return true
-			}
-			if c1.Status != "Finished" && c2.Status == "Finished" {
-				return false
-			}
-			return c1.Status < c2.Status
-		}
-
-		if c1.Status == "Finished" && c2.Status == "Finished" {
-			end1, _ := model.ParseTime(c1.EndTime)
-			planned1, _ := model.ParseTime(c1.PlannedStartTime)
-
-			end2, _ := model.ParseTime(c2.EndTime)
-			planned2, _ := model.ParseTime(c2.PlannedStartTime)
-
-			totalTime1 := end1.Sub(planned1)
-			for _, lap := range c1.LapTimes {
-				lapDuration, _ := time.ParseDuration(strings.Replace(lap.Time, ":", "h", 1) + "m")
-				totalTime1 += lapDuration
-			}
-
-			totalTime2 := end2.Sub(planned2)
-			for _, lap := range c2.LapTimes {
-				lapDuration, _ := time.ParseDuration(strings.Replace(lap.Time, ":", "h", 1) + "m")
-				totalTime2 += lapDuration
-			}
-
-			return totalTime1 < totalTime2
-		}
-
-		return c1.ID < c2.ID
-	})
+	sortedCompetitors := rankOrder(competitors, cfg)
 
 	var report strings.Builder
 
 	for _, competitor := range sortedCompetitors {
 		lapTimesStr := "["
 		for i, lap := range competitor.LapTimes {
-			if lap.Time != "" {
+			// При кругах разной длины в отчёт добавляется дистанция круга
+			switch {
+			case len(cfg.LapList) > 0 && lap.Time != "":
+				lapTimesStr += fmt.Sprintf("{%s, %.3f, %dm}", lap.Time, math.Floor(lap.Speed*1000)/1000, lap.Distance)
+			case len(cfg.LapList) > 0:
+				lapTimesStr += fmt.Sprintf("{,, %dm}", cfg.LapLength(i+1))
+			case lap.Time != "":
 				lapTimesStr += fmt.Sprintf("{%s, %.3f}", lap.Time, math.Floor(lap.Speed*1000)/1000)
-			} else {
+			default:
 				lapTimesStr += "{,}"
 			}
 
@@ -82,23 +47,90 @@ func GenerateFinalReport(competitors map[int]*model.Competitor, cfg *config.Conf
 		hitsStr := fmt.Sprintf("%d/%d", competitor.HitsCount, competitor.ShotsCount)
 
 		var statusStr string
-		if competitor.Status == "" || competitor.Status == "Finished" {
-			end, _ := model.ParseTime(competitor.EndTime)
-			planned, _ := model.ParseTime(competitor.PlannedStartTime)
-			totalTime := end.Sub(planned)
-			for _, lap := range competitor.LapTimes {
-				if lap.Time != "" {
-					lapDuration, _ := time.ParseDuration(strings.Replace(lap.Time, ":", "h", 1) + "m")
-					totalTime += lapDuration
-				}
-			}
-			statusStr = fmt.Sprintf("[%s]", model.FormatDuration(totalTime))
+		if competitor.Status == "" || competitor.Status == model.StatusFinished {
+			statusStr = fmt.Sprintf("[%s]", model.FormatDuration(totalTime(competitor)))
 		} else {
 			statusStr = fmt.Sprintf("[%s]", competitor.Status)
 		}
 
-		report.WriteString(fmt.Sprintf("%s %d %s %s %s\n", statusStr, competitor.ID, lapTimesStr, penaltyStr, hitsStr))
+		// Штрафы и бонусы жюри уже учтены в итоговом времени и перечисляются отдельно
+		adjustmentsStr := ""
+		for _, adjustment := range competitor.TimeAdjustments {
+			adjustmentsStr += fmt.Sprintf(" {%s %s}", model.FormatSignedDuration(adjustment.Duration), adjustment.Reason)
+		}
+
+		// Для дисквалифицированных выводится причина и комментарий жюри
+		reasonStr := ""
+		if competitor.Status == model.StatusDisqualified && competitor.Disqualification != nil {
+			reasonStr = fmt.Sprintf(" %s %s", model.ShortStatus(competitor.Status), competitor.Disqualification.Reason)
+			if competitor.Disqualification.Note != "" {
+				reasonStr += ": " + competitor.Disqualification.Note
+			}
+		}
+
+		report.WriteString(fmt.Sprintf("%s %d %s %s %s%s%s\n", statusStr, competitor.ID, lapTimesStr, penaltyStr, hitsStr, adjustmentsStr, reasonStr))
 	}
 
 	return report.String()
 }
+
+// totalTime возвращает итоговое время участника с учётом штрафов и бонусов жюри
+func totalTime(competitor *model.Competitor) time.Duration {
+	end, _ := model.ParseTime(competitor.EndTime)
+	planned, _ := model.ParseTime(raceStart(competitor))
+	total := end.Sub(planned)
+	for _, lap := range competitor.LapTimes {
+		if lap.Time != "" {
+			lapDuration, _ := time.ParseDuration(strings.Replace(lap.Time, ":", "h", 1) + "m")
+			total += lapDuration
+		}
+	}
+	return total + competitor.TotalAdjustment()
+}
+
+// raceStart возвращает начало отсчёта итогового времени: в гонке преследования в него входит стартовое отставание
+func raceStart(competitor *model.Competitor) string {
+	if competitor.RaceStartTime != "" {
+		return competitor.RaceStartTime
+	}
+	return competitor.PlannedStartTime
+}
+
+// rankOrder упорядочивает участников как в итоговом отчёте: финишировавшие по времени, при равном времени -
+// по порядку финиша, в масс-старте и преследовании - только по порядку финиша, затем остальные по статусу
+func rankOrder(competitors map[int]*model.Competitor, cfg *config.Config) []*model.Competitor {
+	var sortedCompetitors []*model.Competitor
+	for _, competitor := range competitors {
+		sortedCompetitors = append(sortedCompetitors, competitor)
+	}
+
+	sort.Slice(sortedCompetitors, func(i, j int) bool {
+		c1, c2 := sortedCompetitors[i], sortedCompetitors[j]
+
+		if c1.Status != c2.Status {
+			if c1.Status == model.StatusFinished && c2.Status != model.StatusFinished {
+				return true
+			}
+			if c1.Status != model.StatusFinished && c2.Status == model.StatusFinished {
+				return false
+			}
+			return c1.Status < c2.Status
+		}
+
+		if c1.Status == model.StatusFinished && c2.Status == model.StatusFinished {
+			if cfg.IsHeadToHead() && c1.FinishOrder > 0 && c2.FinishOrder > 0 && c1.FinishOrder != c2.FinishOrder {
+				return c1.FinishOrder < c2.FinishOrder
+			}
+			if t1, t2 := totalTime(c1), totalTime(c2); t1 != t2 {
+				return t1 < t2
+			}
+			if c1.FinishOrder != c2.FinishOrder {
+				return c1.FinishOrder < c2.FinishOrder
+			}
+		}
+
+		return c1.ID < c2.ID
+	})
+
+	return sortedCompetitors
+}
@@ -3,8 +3,10 @@ package report
 import (
 	"biathlon/config"
 	"biathlon/model"
+	"biathlon/roster"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestReportSorting(t *testing.T) {
@@ -29,3 +31,336 @@ func TestReportSorting(t *testing.T) {
 		t.Error("Incorrect sorting order")
 	}
 }
+
+func TestTimeAdjustments(t *testing.T) {
+	cfg := &config.Config{Laps: 1}
+
+	competitors := map[int]*model.Competitor{
+		1: {ID: 1, Status: model.StatusFinished, PlannedStartTime: "10:00:00.000", EndTime: "10:20:00.000",
+			TimeAdjustments: []model.TimeAdjustment{{Duration: 2 * time.Minute, Reason: "WrongLane"}}},
+		2: {ID: 2, Status: model.StatusFinished, PlannedStartTime: "10:00:30.000", EndTime: "10:21:30.000"},
+		3: {ID: 3, Status: model.StatusFinished, PlannedStartTime: "10:01:00.000", EndTime: "10:23:30.000",
+			TimeAdjustments: []model.TimeAdjustment{{Duration: -time.Minute, Reason: "Timing"}}},
+	}
+
+	lines := strings.Split(strings.TrimSpace(GenerateFinalReport(competitors, cfg)), "\n")
+	want := []string{
+		"[00:21:00.000] 2 [] {,} 0/0",
+		"[00:21:30.000] 3 [] {,} 0/0 {-00:01:00.000 Timing}",
+		"[00:22:00.000] 1 [] {,} 0/0 {+00:02:00.000 WrongLane}",
+	}
+	if strings.Join(lines, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Unexpected report:\n%s\nwant:\n%s", strings.Join(lines, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestMassStartRankByFinishOrder(t *testing.T) {
+	cfg := &config.Config{Laps: 1, Mode: config.ModeMassStart}
+
+	// Штраф жюри делает итоговое время первого финишёра больше, чем у второго,
+	// но в масс-старте место определяет порядок пересечения финиша
+	competitors := map[int]*model.Competitor{
+		1: {ID: 1, Status: model.StatusFinished, PlannedStartTime: "10:00:00.000", EndTime: "10:20:00.000", FinishOrder: 1,
+			TimeAdjustments: []model.TimeAdjustment{{Duration: time.Minute, Reason: "Jury"}}},
+		2: {ID: 2, Status: model.StatusFinished, PlannedStartTime: "10:00:00.000", EndTime: "10:20:30.000", FinishOrder: 2},
+	}
+
+	results := Results(competitors, cfg)
+	if results[0].CompetitorID != 1 || results[0].Rank != 1 || results[1].CompetitorID != 2 || results[1].Rank != 2 {
+		t.Errorf("Expected finish order [1 2], got %+v", results)
+	}
+
+	cfg.Mode = config.ModeIndividual
+	results = Results(competitors, cfg)
+	if results[0].CompetitorID != 2 || results[1].CompetitorID != 1 {
+		t.Errorf("Expected individual race ranked by time [2 1], got %+v", results)
+	}
+}
+
+func TestResultsUnfinished(t *testing.T) {
+	cfg := &config.Config{Laps: 1}
+
+	competitors := map[int]*model.Competitor{
+		1: {ID: 1, Status: model.StatusFinished, PlannedStartTime: "10:00:00.000", EndTime: "10:20:00.000"},
+		2: {ID: 2, PlannedStartTime: "10:00:30.000", ActualStartTime: "10:00:31.000"},
+		3: {ID: 3},
+	}
+
+	results := Results(competitors, cfg)
+	statuses := map[int]string{}
+	for _, result := range results {
+		statuses[result.CompetitorID] = result.Status
+		if result.CompetitorID != 1 && (result.Rank != 0 || result.TotalTime != "") {
+			t.Errorf("Unfinished competitor must not be ranked: %+v", result)
+		}
+	}
+	if results[0].CompetitorID != 1 || results[0].Rank != 1 {
+		t.Errorf("Expected finisher first, got %+v", results[0])
+	}
+	if statuses[2] != model.StatusRunning || statuses[3] != model.StatusRegistered {
+		t.Errorf("Unexpected statuses: %v", statuses)
+	}
+}
+
+func TestTeamClassification(t *testing.T) {
+	r := roster.Roster{
+		1: {ID: 1, Name: "Anna", Nation: "NOR", Club: "Oslo"},
+		2: {ID: 2, Name: "Bea", Nation: "GER", Club: "Ruhpolding"},
+		3: {ID: 3, Name: "Cleo", Nation: "GER", Club: "Oslo"},
+		4: {ID: 4, Name: "Dora", Nation: "NOR", Club: "Oslo"},
+		5: {ID: 5, Nation: "FRA"},
+		6: {ID: 6, Name: "Eva", Nation: "NOR"},
+		7: {ID: 7, Name: "Fay", Nation: "FRA"},
+	}
+	// Время задано только строкой, как у результатов из хранилища
+	results := []Result{
+		{Rank: 1, CompetitorID: 1, TotalTime: "00:20:00.000"},
+		{Rank: 2, CompetitorID: 2, TotalTime: "00:20:10.000"},
+		{Rank: 3, CompetitorID: 3, TotalTime: "00:20:30.000"},
+		{Rank: 4, CompetitorID: 4, TotalTime: "00:20:40.000"},
+		{Rank: 5, CompetitorID: 5, TotalTime: "00:21:00.000"},
+		{Rank: 6, CompetitorID: 6, TotalTime: "00:22:00.000"},
+		{CompetitorID: 7, Status: model.StatusNotFinished},
+	}
+
+	// Равная сумма времени: выше NOR, у которой лучший спортсмен первый
+	opts := TeamOptions{Size: 2, By: TeamByNation, Scoring: TeamScoringTime}
+	got := strings.TrimSpace(GenerateTeamReport(TeamClassification(results, r, opts), opts))
+	want := strings.Join([]string{
+		"1. NOR 00:40:40.000 (1 Anna 00:20:00.000, 4 Dora 00:20:40.000)",
+		"2. GER 00:40:40.000 (2 Bea 00:20:10.000, 3 Cleo 00:20:30.000)",
+		"3. FRA 00:21:00.000 [1/2] (5 competitor(5) 00:21:00.000)",
+	}, "\n")
+	if got != want {
+		t.Errorf("Unexpected time classification:\n%s\nwant:\n%s", got, want)
+	}
+
+	opts = TeamOptions{Size: 2, By: TeamByNation, Scoring: TeamScoringPoints, Points: WorldCupPoints}
+	standings := TeamClassification(results, r, opts)
+	if standings[0].Team != "NOR" || standings[0].Points != 145 || standings[1].Team != "GER" || standings[1].Points != 140 {
+		t.Errorf("Unexpected points classification: %+v", standings)
+	}
+
+	// Спортсмены без клуба в зачёт клубов не попадают
+	opts = TeamOptions{Size: 3, By: TeamByClub, Scoring: TeamScoringTime}
+	standings = TeamClassification(results, r, opts)
+	if len(standings) != 2 || standings[0].Team != "Oslo" || len(standings[0].Members) != 3 || !standings[0].Complete ||
+		standings[1].Team != "Ruhpolding" || standings[1].Complete {
+		t.Errorf("Unexpected club classification: %+v", standings)
+	}
+
+	// Полное равенство - общее место
+	tied := []Result{
+		{Rank: 1, CompetitorID: 1, TotalTime: "00:20:00.000"},
+		{Rank: 1, CompetitorID: 2, TotalTime: "00:20:00.000"},
+		{Rank: 3, CompetitorID: 4, TotalTime: "00:21:00.000"},
+		{Rank: 3, CompetitorID: 3, TotalTime: "00:21:00.000"},
+	}
+	opts = TeamOptions{Size: 2, By: TeamByNation, Scoring: TeamScoringPoints, Points: WorldCupPoints}
+	standings = TeamClassification(tied, r, opts)
+	if len(standings) != 2 || standings[0].Rank != 1 || standings[1].Rank != 1 || standings[0].Team != "GER" {
+		t.Errorf("Unexpected tied classification: %+v", standings)
+	}
+}
+
+func TestResultsHTML(t *testing.T) {
+	cfg := &config.Config{Laps: 1, LapLen: 3000, FiringLines: 1, Start: "10:00:00.000"}
+
+	competitors := map[int]*model.Competitor{
+		1: {ID: 1, Status: model.StatusFinished, PlannedStartTime: "10:00:00.000", EndTime: "10:20:00.000",
+			LapTimes:    []model.LapInfo{{Time: "00:20:00.000", Speed: 2.5}},
+			RangeVisits: []model.RangeVisit{{Lap: 1, FiringRange: 1, Hits: 4}}},
+		2: {ID: 2, Status: model.StatusNotFinished, CannotContinue: "Broken ski"},
+		3: {ID: 3, Status: model.StatusNotStarted},
+		4: {ID: 4, Status: model.StatusDisqualified, Disqualification: &model.Disqualification{Reason: "IllegalEquipment"}},
+	}
+	r := roster.Roster{1: {ID: 1, Name: "<Anna>", Nation: "NOR"}}
+
+	page, err := GenerateResultsHTML(competitors, cfg, HTMLOptions{Title: "Club Cup", Roster: r})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, want := range []string{
+		"<title>Club Cup</title>",
+		"<td>&lt;Anna&gt;</td>",
+		`<td class="num miss" data-sort="4">4</td>`,
+		"Did not finish (DNF)", "<td>Broken ski</td>",
+		"Did not start (DNS)",
+		"Disqualified (DSQ)", "<td>IllegalEquipment</td>",
+		"@media print",
+	} {
+		if !strings.Contains(page, want) {
+			t.Errorf("Page does not contain %q", want)
+		}
+	}
+	for _, external := range []string{"src=", "href=", "http://", "https://"} {
+		if strings.Contains(page, external) {
+			t.Errorf("Page references external asset: %q", external)
+		}
+	}
+}
+
+func TestResultSheet(t *testing.T) {
+	air, snow := -4.5, -7.0
+	cfg := &config.Config{Laps: 1, LapLen: 3000, PenaltyLen: 150, FiringLines: 1, Start: "10:00:00.000",
+		Info: &config.RaceInfo{
+			Name: "Women 3 km Sprint", Competition: "Club Cup", Venue: "Tyumen", Date: "2026-10-19",
+			Jury:    []config.JuryMember{{Role: "Technical Delegate", Name: "Ivan Petrov", Nation: "RUS"}},
+			Weather: &config.Weather{Conditions: "Cloudy", AirTemperature: &air, SnowTemperature: &snow},
+			Course:  &config.CourseInfo{Homologation: "H-042", HeightDifference: 38},
+		}}
+
+	competitors := map[int]*model.Competitor{
+		1: {ID: 1, Status: model.StatusFinished, PlannedStartTime: "10:00:00.000", EndTime: "10:20:00.000",
+			LapTimes:    []model.LapInfo{{Time: "00:20:00.000", Speed: 2.5}},
+			RangeVisits: []model.RangeVisit{{Lap: 1, FiringRange: 1, Hits: 5}}},
+		2: {ID: 2, Status: model.StatusNotStarted},
+	}
+
+	page, err := GenerateResultSheet(competitors, cfg, HTMLOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, want := range []string{
+		"<h1>Women 3 km Sprint</h1>", "Club Cup", "Tyumen, 2026-10-19",
+		"<td>Technical Delegate</td><td>Ivan Petrov</td><td>RUS</td>",
+		"<td>Air</td><td>-4.5 °C</td>", "<td>Snow</td><td>-7.0 °C</td>",
+		"<td>Homologation</td><td>H-042</td>", "<td>Distance</td><td>3000 m</td>", "<td>Height difference</td><td>38 m</td>",
+		"Did not start",
+		"Technical Delegate: Ivan Petrov",
+		"@page",
+	} {
+		if !strings.Contains(page, want) {
+			t.Errorf("Sheet does not contain %q", want)
+		}
+	}
+	for _, external := range []string{"<script", "src=", "href=", "http://", "https://"} {
+		if strings.Contains(page, external) {
+			t.Errorf("Sheet references external asset: %q", external)
+		}
+	}
+}
+
+func TestSplitStandings(t *testing.T) {
+	competitors := map[int]*model.Competitor{
+		1: {ID: 1, Splits: []model.Split{
+			{Kind: model.SplitRangeArrival, Index: 1, Lap: 1, Elapsed: 9 * time.Minute},
+			{Kind: model.SplitRangeExit, Index: 1, Lap: 1, Elapsed: 10 * time.Minute},
+			{Kind: model.SplitLapEnd, Index: 1, Lap: 1, Elapsed: 13 * time.Minute},
+		}},
+		2: {ID: 2, Splits: []model.Split{
+			{Kind: model.SplitRangeArrival, Index: 1, Lap: 1, Elapsed: 8 * time.Minute},
+			{Kind: model.SplitRangeExit, Index: 1, Lap: 1, Elapsed: 9 * time.Minute},
+			{Kind: model.SplitPenaltyExit, Index: 1, Lap: 1, Elapsed: 11 * time.Minute},
+			{Kind: model.SplitLapEnd, Index: 1, Lap: 1, Elapsed: 14 * time.Minute},
+		}},
+	}
+
+	names := SplitNames(competitors)
+	want := []string{"shooting 1 arrival", "shooting 1", "penalty 1", "lap 1"}
+	if strings.Join(names, ",") != strings.Join(want, ",") {
+		t.Errorf("SplitNames() = %v, want %v", names, want)
+	}
+
+	standings, err := SplitStandings(competitors, "after shooting 1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if standings[0].Competitor.ID != 2 || standings[1].Competitor.ID != 1 || standings[1].Gap != time.Minute {
+		t.Errorf("Unexpected standings after shooting 1: %+v", standings)
+	}
+
+	// Участник 1 не бежал штрафных кругов и проходит точку сразу после рубежа
+	standings, _ = SplitStandings(competitors, "penalty 1")
+	if len(standings) != 2 || standings[0].Competitor.ID != 1 || standings[0].Elapsed != 10*time.Minute {
+		t.Errorf("Unexpected standings after penalty 1: %+v", standings)
+	}
+
+	standings, _ = SplitStandings(competitors, "lap 1")
+	if standings[0].Competitor.ID != 1 || standings[1].Rank != 2 {
+		t.Errorf("Unexpected standings after lap 1: %+v", standings)
+	}
+
+	if _, err := SplitStandings(competitors, "shooting 3"); err == nil {
+		t.Error("Expected error for unknown split")
+	}
+}
+
+func TestSegmentsReportOrder(t *testing.T) {
+	segment := func(seconds int) []model.Segment {
+		return []model.Segment{{Lap: 1, From: "start", To: "range", Distance: 1000, Time: time.Duration(seconds) * time.Second}}
+	}
+	competitors := map[int]*model.Competitor{
+		1: {ID: 1, Status: model.StatusNotFinished, Segments: segment(100)},
+		2: {ID: 2, Status: model.StatusFinished, Segments: segment(250)},
+		3: {ID: 3, Status: model.StatusFinished, Segments: segment(250)},
+		4: {ID: 4, Status: model.StatusFinished, Segments: segment(200)},
+	}
+
+	var headers []string
+	for _, line := range strings.Split(GenerateSegmentsReport(competitors), "\n") {
+		if strings.HasPrefix(line, "[") {
+			headers = append(headers, line)
+		}
+	}
+	want := []string{"[4] 5.000", "[2] 4.000", "[3] 4.000", "[1] 10.000 DNF"}
+	if strings.Join(headers, ",") != strings.Join(want, ",") {
+		t.Errorf("Segments order = %v, want %v", headers, want)
+	}
+}
+
+func TestAnalyticsRankings(t *testing.T) {
+	competitors := map[int]*model.Competitor{
+		1: {ID: 1, Status: "Finished", SkiTime: 20 * time.Minute, RangeVisits: []model.RangeVisit{
+			{ExitTime: "10:10:00.000", Hits: 5, RangeTime: 40 * time.Second, ShootingTime: 10 * time.Second},
+			{ExitTime: "10:20:00.000", Hits: 1, RangeTime: 30 * time.Second},
+		}},
+		2: {ID: 2, Status: "Finished", SkiTime: 19 * time.Minute, RangeVisits: []model.RangeVisit{
+			{ExitTime: "10:11:00.000", Hits: 3, RangeTime: 32 * time.Second, ShootingTime: 15 * time.Second},
+		}},
+		3: {ID: 3, Status: "NotFinished"},
+	}
+
+	rangeTime := RangeTimeRanking(competitors)
+	if len(rangeTime) != 2 || rangeTime[0].Competitor.ID != 2 || rangeTime[1].Average != 35*time.Second || rangeTime[1].Best != 30*time.Second {
+		t.Errorf("Unexpected range time ranking: %+v", rangeTime)
+	}
+
+	shooting := ShootingTimeRanking(competitors)
+	if len(shooting) != 2 || shooting[0].Competitor.ID != 1 || shooting[0].Count != 1 {
+		t.Errorf("Unexpected shooting time ranking: %+v", shooting)
+	}
+
+	ski := SkiTimeRanking(competitors)
+	if len(ski) != 2 || ski[0].Competitor.ID != 2 {
+		t.Errorf("Unexpected ski time ranking: %+v", ski)
+	}
+}
+
+func TestPositionAccuracy(t *testing.T) {
+	competitors := map[int]*model.Competitor{
+		1: {ID: 1, RangeVisits: []model.RangeVisit{
+			{Position: config.PositionProne, ExitTime: "10:10:00.000", Hits: 5},
+			{Position: config.PositionStanding, ExitTime: "10:20:00.000", Hits: 2},
+		}},
+		2: {ID: 2, RangeVisits: []model.RangeVisit{
+			{Position: config.PositionProne, ExitTime: "10:11:00.000", Hits: 4},
+			{Position: config.PositionStanding, Hits: 1},
+		}},
+	}
+
+	accuracy := PositionAccuracy(competitors[1])
+	if accuracy[config.PositionProne] != (Accuracy{5, 5}) || accuracy[config.PositionStanding] != (Accuracy{2, 5}) {
+		t.Errorf("Unexpected accuracy: %v", accuracy)
+	}
+
+	total := FieldAccuracy(competitors)
+	if total[config.PositionProne] != (Accuracy{9, 10}) || total[config.PositionStanding] != (Accuracy{2, 5}) {
+		t.Errorf("Unexpected field accuracy: %v", total)
+	}
+	if total[config.PositionProne].String() != "9/10 90.0%" {
+		t.Errorf("Unexpected accuracy format: %s", total[config.PositionProne])
+	}
+}
@@ -0,0 +1,163 @@
+package report
+
+import (
+	"encoding/json"
+	"math"
+	"time"
+
+	"biathlon/config"
+	"biathlon/model"
+)
+
+// WorldCupPoints - таблица очков Кубка мира: 90-75-65-55-50-45-41-37-34-31, далее от 30 до 1 за 11-40 места
+var WorldCupPoints = worldCupPoints()
+
+func worldCupPoints() []int {
+	points := []int{90, 75, 65, 55, 50, 45, 41, 37, 34, 31}
+	for p := 30; p >= 1; p-- {
+		points = append(points, p)
+	}
+	return points
+}
+
+// PointsFor возвращает очки за место по таблице; места за пределами таблицы очков не приносят
+func PointsFor(points []int, rank int) int {
+	if rank >= 1 && rank <= len(points) {
+		return points[rank-1]
+	}
+	return 0
+}
+
+// Result - строка итогового протокола в машиночитаемом виде
+type Result struct {
+	Rank         int                `json:"rank,omitempty"`
+	CompetitorID int                `json:"competitorId"`
+	Status       string             `json:"status"`
+	Time         time.Duration      `json:"-"`
+	TotalTime    string             `json:"totalTime,omitempty"`
+	Laps         []LapResult        `json:"laps"`
+	PenaltyTime  string             `json:"penaltyTime,omitempty"`
+	PenaltySpeed float64            `json:"penaltySpeed,omitempty"`
+	Hits         int                `json:"hits"`
+	Shots        int                `json:"shots"`
+	Shooting     []StageResult      `json:"shooting,omitempty"`
+	Adjustments  []AdjustmentResult `json:"adjustments,omitempty"`
+	Reason       string             `json:"reason,omitempty"`
+	Note         string             `json:"note,omitempty"`
+}
+
+// Duration возвращает итоговое время; для результатов, прочитанных из JSON, оно восстанавливается из TotalTime
+func (r Result) Duration() time.Duration {
+	if r.Time != 0 || r.TotalTime == "" {
+		return r.Time
+	}
+	t, err := model.ParseTime(r.TotalTime)
+	if err != nil {
+		return 0
+	}
+	return t.Sub(time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location()))
+}
+
+type LapResult struct {
+	Time     string  `json:"time,omitempty"`
+	Speed    float64 `json:"speed,omitempty"`
+	Distance int     `json:"distance"`
+}
+
+// StageResult - стрельба на одном огневом рубеже
+type StageResult struct {
+	Lap       int    `json:"lap"`
+	Lane      int    `json:"lane"`
+	Position  string `json:"position,omitempty"`
+	Hits      int    `json:"hits"`
+	RangeTime string `json:"rangeTime,omitempty"`
+}
+
+type AdjustmentResult struct {
+	Time     string `json:"time"`
+	Duration string `json:"duration"`
+	Reason   string `json:"reason"`
+	Note     string `json:"note,omitempty"`
+}
+
+// Results возвращает итоговый протокол в порядке отчёта. Место присваивается только финишировавшим,
+// участники без итогового статуса получают StatusRunning или StatusRegistered; при равном времени места совпадают; в масс-старте и преследовании места определяет порядок финиша.
+func Results(competitors map[int]*model.Competitor, cfg *config.Config) []Result {
+	results := make([]Result, 0, len(competitors))
+
+	for _, competitor := range rankOrder(competitors, cfg) {
+		result := Result{
+			CompetitorID: competitor.ID,
+			Status:       competitor.Status,
+			Hits:         competitor.HitsCount,
+			Shots:        competitor.ShotsCount,
+		}
+
+		switch {
+		case competitor.Status == "" && competitor.ActualStartTime != "":
+			result.Status = model.StatusRunning
+		case competitor.Status == "":
+			result.Status = model.StatusRegistered
+		case competitor.Status == model.StatusFinished:
+			result.Time = totalTime(competitor)
+			result.TotalTime = model.FormatDuration(result.Time)
+
+			result.Rank = len(results) + 1
+			if prev := len(results) - 1; !cfg.IsHeadToHead() && prev >= 0 && results[prev].Rank > 0 && results[prev].Time == result.Time {
+				result.Rank = results[prev].Rank
+			}
+		}
+
+		for i, lap := range competitor.LapTimes {
+			lapResult := LapResult{Distance: cfg.LapLength(i + 1)}
+			if lap.Time != "" {
+				lapResult.Time = lap.Time
+				lapResult.Speed = math.Floor(lap.Speed*1000) / 1000
+			}
+			result.Laps = append(result.Laps, lapResult)
+		}
+
+		if competitor.PenaltyStartTime != "" {
+			result.PenaltyTime = model.FormatDuration(competitor.PenaltyDuration)
+			result.PenaltySpeed = math.Floor(competitor.PenaltySpeed*1000) / 1000
+		}
+
+		for _, visit := range competitor.RangeVisits {
+			stage := StageResult{Lap: visit.Lap, Lane: visit.FiringRange, Position: visit.Position, Hits: visit.Hits}
+			if visit.ExitTime != "" {
+				stage.RangeTime = model.FormatDuration(visit.RangeTime)
+			}
+			result.Shooting = append(result.Shooting, stage)
+		}
+
+		for _, adjustment := range competitor.TimeAdjustments {
+			result.Adjustments = append(result.Adjustments, AdjustmentResult{
+				Time:     adjustment.Time,
+				Duration: model.FormatSignedDuration(adjustment.Duration),
+				Reason:   adjustment.Reason,
+				Note:     adjustment.Note,
+			})
+		}
+
+		switch {
+		case competitor.Disqualification != nil:
+			result.Reason = competitor.Disqualification.Reason
+			result.Note = competitor.Disqualification.Note
+		case competitor.Status == model.StatusNotFinished:
+			result.Note = competitor.CannotContinue
+		}
+
+		results = append(results, result)
+	}
+
+	return results
+}
+
+// GenerateResultsJSON возвращает итоговый протокол в формате JSON
+func GenerateResultsJSON(competitors map[int]*model.Competitor, cfg *config.Config) (string, error) {
+	data, err := json.MarshalIndent(Results(competitors, cfg), "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(data) + "\n", nil
+}
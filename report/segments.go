@@ -0,0 +1,70 @@
+package report
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"biathlon/model"
+)
+
+// OverallSkiSpeed возвращает среднюю ходовую скорость по всем пройденным участкам
+func OverallSkiSpeed(competitor *model.Competitor) float64 {
+	distance := 0
+	var duration time.Duration
+	for _, segment := range competitor.Segments {
+		distance += segment.Distance
+		duration += segment.Time
+	}
+
+	if duration.Seconds() <= 0 {
+		return 0
+	}
+	return float64(distance) / duration.Seconds()
+}
+
+// GenerateSegmentsReport выводит участки трассы участников по убыванию средней ходовой скорости.
+// Не финишировавшие идут после финишировавших с пометкой статуса: их скорость посчитана по части дистанции.
+func GenerateSegmentsReport(competitors map[int]*model.Competitor) string {
+	var sortedCompetitors []*model.Competitor
+	for _, competitor := range competitors {
+		if len(competitor.Segments) > 0 {
+			sortedCompetitors = append(sortedCompetitors, competitor)
+		}
+	}
+
+	sort.SliceStable(sortedCompetitors, func(i, j int) bool {
+		c1, c2 := sortedCompetitors[i], sortedCompetitors[j]
+		if f1, f2 := c1.Status == model.StatusFinished, c2.Status == model.StatusFinished; f1 != f2 {
+			return f1
+		}
+		if s1, s2 := OverallSkiSpeed(c1), OverallSkiSpeed(c2); s1 != s2 {
+			return s1 > s2
+		}
+		return c1.ID < c2.ID
+	})
+
+	var report strings.Builder
+	for i, competitor := range sortedCompetitors {
+		if i > 0 {
+			report.WriteString("\n")
+		}
+		status := ""
+		switch competitor.Status {
+		case model.StatusFinished:
+		case "":
+			status = " " + model.StatusRunning
+		default:
+			status = " " + model.ShortStatus(competitor.Status)
+		}
+		report.WriteString(fmt.Sprintf("[%d] %.3f%s\n", competitor.ID, math.Floor(OverallSkiSpeed(competitor)*1000)/1000, status))
+		for _, segment := range competitor.Segments {
+			report.WriteString(fmt.Sprintf("lap %d %s %dm {%s, %.3f}\n", segment.Lap, segment.Name(), segment.Distance,
+				model.FormatDuration(segment.Time), math.Floor(segment.Speed*1000)/1000))
+		}
+	}
+
+	return report.String()
+}
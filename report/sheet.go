@@ -0,0 +1,122 @@
+package report
+
+import (
+	_ "embed"
+	"fmt"
+	"html/template"
+	"strings"
+
+	"biathlon/config"
+	"biathlon/model"
+)
+
+//go:embed sheet.html
+var sheetHTML string
+
+var sheetTemplate = template.Must(template.New("sheet").Parse(sheetHTML))
+
+type sheetPage struct {
+	htmlPage
+	Competition    string
+	Venue          string
+	Date           string
+	Jury           []config.JuryMember
+	Weather        []sheetField
+	Course         []sheetField
+	HasAdjustments bool
+}
+
+type sheetField struct {
+	Label string
+	Value string
+}
+
+// GenerateResultSheet возвращает официальный протокол для печати и подписи жюри: шапку со сведениями
+// о гонке из cfg.Info (жюри, погода, данные трассы), таблицу мест, списки DSQ/DNF/DNS и места для подписей
+func GenerateResultSheet(competitors map[int]*model.Competitor, cfg *config.Config, opts HTMLOptions) (string, error) {
+	page := sheetPage{htmlPage: newHTMLPage(competitors, cfg, opts)}
+
+	info := cfg.Info
+	if info == nil {
+		info = &config.RaceInfo{}
+	}
+	if opts.Title == "" {
+		page.Title = info.Name
+	}
+	if page.Title == "" {
+		page.Title = "Official Results"
+	}
+	page.Competition, page.Venue, page.Date, page.Jury = info.Competition, info.Venue, info.Date, info.Jury
+	page.Weather = weatherFields(info.Weather)
+	page.Course = courseFields(cfg, info.Course)
+
+	for _, row := range page.Ranked {
+		if row.Jury != "" {
+			page.HasAdjustments = true
+		}
+	}
+
+	var b strings.Builder
+	if err := sheetTemplate.Execute(&b, page); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+func weatherFields(w *config.Weather) []sheetField {
+	if w == nil {
+		return nil
+	}
+
+	var fields []sheetField
+	add := func(label, value string) {
+		if value != "" {
+			fields = append(fields, sheetField{label, value})
+		}
+	}
+	degrees := func(t *float64) string {
+		if t == nil {
+			return ""
+		}
+		return fmt.Sprintf("%.1f °C", *t)
+	}
+
+	add("Conditions", w.Conditions)
+	add("Air", degrees(w.AirTemperature))
+	add("Snow", degrees(w.SnowTemperature))
+	if w.Humidity != nil {
+		add("Humidity", fmt.Sprintf("%.0f %%", *w.Humidity))
+	}
+	add("Wind", w.Wind)
+	return fields
+}
+
+// courseFields дополняет данные омологации длиной дистанции и штрафного круга из конфигурации
+func courseFields(cfg *config.Config, course *config.CourseInfo) []sheetField {
+	var fields []sheetField
+	add := func(label, value string) {
+		if value != "" {
+			fields = append(fields, sheetField{label, value})
+		}
+	}
+	meters := func(m int) string {
+		if m <= 0 {
+			return ""
+		}
+		return fmt.Sprintf("%d m", m)
+	}
+
+	if course != nil {
+		add("Course", course.Name)
+		add("Homologation", course.Homologation)
+	}
+	add("Distance", meters(cfg.TotalDistance()))
+	add("Laps", fmt.Sprint(cfg.Laps))
+	add("Penalty loop", meters(cfg.PenaltyLen))
+	if course != nil {
+		add("Height difference", meters(course.HeightDifference))
+		add("Max climb", meters(course.MaxClimb))
+		add("Total climb", meters(course.TotalClimb))
+	}
+	return fields
+}
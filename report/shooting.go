@@ -0,0 +1,90 @@
+package report
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"biathlon/config"
+	"biathlon/model"
+)
+
+type Accuracy struct {
+	Hits  int
+	Shots int
+}
+
+func (a Accuracy) Percent() float64 {
+	if a.Shots == 0 {
+		return 0
+	}
+	return float64(a.Hits) * 100 / float64(a.Shots)
+}
+
+func (a Accuracy) String() string {
+	if a.Shots == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%d/%d %.1f%%", a.Hits, a.Shots, a.Percent())
+}
+
+// PositionAccuracy возвращает точность стрельбы участника по положениям (лёжа/стоя)
+func PositionAccuracy(competitor *model.Competitor) map[string]Accuracy {
+	accuracy := make(map[string]Accuracy)
+	for _, visit := range competitor.RangeVisits {
+		if visit.Position == "" || visit.ExitTime == "" {
+			continue
+		}
+		a := accuracy[visit.Position]
+		a.Hits += min(visit.Hits, 5)
+		a.Shots += 5
+		accuracy[visit.Position] = a
+	}
+	return accuracy
+}
+
+// FieldAccuracy возвращает суммарную точность всех участников по положениям
+func FieldAccuracy(competitors map[int]*model.Competitor) map[string]Accuracy {
+	total := make(map[string]Accuracy)
+	for _, competitor := range competitors {
+		for position, a := range PositionAccuracy(competitor) {
+			t := total[position]
+			t.Hits += a.Hits
+			t.Shots += a.Shots
+			total[position] = t
+		}
+	}
+	return total
+}
+
+func GenerateShootingReport(competitors map[int]*model.Competitor) string {
+	total := FieldAccuracy(competitors)
+	if len(total) == 0 {
+		return ""
+	}
+
+	var ids []int
+	for id, competitor := range competitors {
+		if len(competitor.RangeVisits) > 0 {
+			ids = append(ids, id)
+		}
+	}
+	sort.Ints(ids)
+
+	positions := []string{config.PositionProne, config.PositionStanding}
+	formatLine := func(label string, accuracy map[string]Accuracy) string {
+		parts := make([]string, len(positions))
+		for i, position := range positions {
+			parts[i] = fmt.Sprintf("%s %s", position, accuracy[position])
+		}
+		return fmt.Sprintf("[%s] %s\n", label, strings.Join(parts, " "))
+	}
+
+	var report strings.Builder
+	for _, id := range ids {
+		report.WriteString(formatLine(fmt.Sprint(id), PositionAccuracy(competitors[id])))
+	}
+	report.WriteString(formatLine("total", total))
+
+	return report.String()
+}
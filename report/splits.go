@@ -0,0 +1,146 @@
+package report
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"biathlon/model"
+)
+
+type Standing struct {
+	Rank       int
+	Competitor *model.Competitor
+	Elapsed    time.Duration
+	Gap        time.Duration
+}
+
+var splitKindOrder = map[string]int{
+	model.SplitRangeArrival: 0,
+	model.SplitRangeExit:    1,
+	model.SplitPenaltyExit:  2,
+	model.SplitLapEnd:       3,
+}
+
+// SplitNames возвращает имена всех точек хронометража в порядке прохождения дистанции
+func SplitNames(competitors map[int]*model.Competitor) []string {
+	seen := make(map[string]model.Split)
+	for _, competitor := range competitors {
+		for _, split := range competitor.Splits {
+			if _, ok := seen[split.Name()]; !ok {
+				seen[split.Name()] = split
+			}
+		}
+	}
+
+	splits := make([]model.Split, 0, len(seen))
+	for _, split := range seen {
+		splits = append(splits, split)
+	}
+
+	sort.Slice(splits, func(i, j int) bool {
+		s1, s2 := splits[i], splits[j]
+		if s1.Lap != s2.Lap {
+			return s1.Lap < s2.Lap
+		}
+		if splitKindOrder[s1.Kind] != splitKindOrder[s2.Kind] {
+			return splitKindOrder[s1.Kind] < splitKindOrder[s2.Kind]
+		}
+		return s1.Index < s2.Index
+	})
+
+	names := make([]string, len(splits))
+	for i, split := range splits {
+		names[i] = split.Name()
+	}
+	return names
+}
+
+// SplitStandings возвращает положение участников на точке хронометража с отставанием от лидера.
+// Имя точки можно указывать с префиксом "after", например "after shooting 2".
+func SplitStandings(competitors map[int]*model.Competitor, name string) ([]Standing, error) {
+	name = normalizeSplitName(name)
+
+	var standings []Standing
+	for _, competitor := range competitors {
+		if elapsed, ok := passedSplit(competitor, name); ok {
+			standings = append(standings, Standing{Competitor: competitor, Elapsed: elapsed})
+		}
+	}
+
+	if len(standings) == 0 {
+		return nil, fmt.Errorf("unknown split: %q", name)
+	}
+
+	sort.Slice(standings, func(i, j int) bool {
+		if standings[i].Elapsed != standings[j].Elapsed {
+			return standings[i].Elapsed < standings[j].Elapsed
+		}
+		return standings[i].Competitor.ID < standings[j].Competitor.ID
+	})
+
+	for i := range standings {
+		standings[i].Rank = i + 1
+		standings[i].Gap = standings[i].Elapsed - standings[0].Elapsed
+	}
+
+	return standings, nil
+}
+
+// GenerateSplitsReport выводит таблицы положения на указанных точках, а без имён - на всех
+func GenerateSplitsReport(competitors map[int]*model.Competitor, names ...string) (string, error) {
+	if len(names) == 0 {
+		names = SplitNames(competitors)
+	}
+
+	var report strings.Builder
+	for i, name := range names {
+		standings, err := SplitStandings(competitors, name)
+		if err != nil {
+			return "", err
+		}
+
+		if i > 0 {
+			report.WriteString("\n")
+		}
+		report.WriteString(fmt.Sprintf("[%s]\n", normalizeSplitName(name)))
+		for _, standing := range standings {
+			report.WriteString(fmt.Sprintf("%d %d %s +%s\n", standing.Rank, standing.Competitor.ID,
+				model.FormatDuration(standing.Elapsed), model.FormatDuration(standing.Gap)))
+		}
+	}
+
+	return report.String(), nil
+}
+
+// passedSplit возвращает время участника на точке. Штрафные круги проходят не все: отстрелявший без
+// промахов или уже ушедший дальше по дистанции проходит точку "penalty N" сразу после рубежа.
+func passedSplit(competitor *model.Competitor, name string) (time.Duration, bool) {
+	for _, split := range competitor.Splits {
+		if split.Name() == name {
+			return split.Elapsed, true
+		}
+	}
+
+	var stage int
+	if _, err := fmt.Sscanf(name, model.SplitPenaltyExit+" %d", &stage); err != nil {
+		return 0, false
+	}
+	rangeExit := model.Split{Kind: model.SplitRangeExit, Index: stage}.Name()
+	for i, split := range competitor.Splits {
+		if split.Name() != rangeExit {
+			continue
+		}
+		clean := stage <= len(competitor.RangeVisits) && competitor.RangeVisits[stage-1].Hits >= model.TargetsPerVisit
+		if clean || i < len(competitor.Splits)-1 {
+			return split.Elapsed, true
+		}
+	}
+	return 0, false
+}
+
+func normalizeSplitName(name string) string {
+	name = strings.ToLower(strings.Join(strings.Fields(name), " "))
+	return strings.TrimPrefix(name, "after ")
+}
@@ -0,0 +1,94 @@
+package report
+
+import (
+	"fmt"
+	"strings"
+
+	"biathlon/config"
+	"biathlon/model"
+)
+
+// StageStandings возвращает положение участников после каждого огневого рубежа и на финише.
+// Имена этапов: "shooting 1", "shooting 2", ..., "finish".
+func StageStandings(competitors map[int]*model.Competitor, cfg *config.Config) ([]string, [][]Standing) {
+	stages := 0
+	for _, competitor := range competitors {
+		for _, split := range competitor.Splits {
+			if split.Kind == model.SplitRangeExit {
+				stages = max(stages, split.Index)
+			}
+		}
+	}
+
+	var names []string
+	var standings [][]Standing
+	for stage := 1; stage <= stages; stage++ {
+		name := fmt.Sprintf("%s %d", model.SplitRangeExit, stage)
+		stageStandings, err := SplitStandings(competitors, name)
+		if err != nil {
+			continue
+		}
+		names = append(names, name)
+		standings = append(standings, stageStandings)
+	}
+
+	var finish []Standing
+	for _, competitor := range rankOrder(competitors, cfg) {
+		if competitor.Status != model.StatusFinished {
+			break
+		}
+		end, _ := model.ParseTime(competitor.EndTime)
+		start, _ := model.ParseTime(raceStart(competitor))
+
+		standing := Standing{Rank: len(finish) + 1, Competitor: competitor, Elapsed: end.Sub(start)}
+		if len(finish) > 0 {
+			standing.Gap = standing.Elapsed - finish[0].Elapsed
+		}
+		finish = append(finish, standing)
+	}
+	if len(finish) > 0 {
+		names = append(names, "finish")
+		standings = append(standings, finish)
+	}
+
+	return names, standings
+}
+
+// GenerateStagesReport выводит положение после каждого рубежа и на финише с изменением позиции
+// относительно предыдущего этапа: (+2) - отыграл два места, (-1) - потерял одно, (=) - без изменений
+func GenerateStagesReport(competitors map[int]*model.Competitor, cfg *config.Config) string {
+	names, standings := StageStandings(competitors, cfg)
+
+	var report strings.Builder
+	previous := make(map[int]int)
+	for i, name := range names {
+		if i > 0 {
+			report.WriteString("\n")
+		}
+		report.WriteString(fmt.Sprintf("[%s]\n", name))
+
+		current := make(map[int]int)
+		for _, standing := range standings[i] {
+			id := standing.Competitor.ID
+			current[id] = standing.Rank
+
+			report.WriteString(fmt.Sprintf("%d %d %s +%s%s\n", standing.Rank, id,
+				model.FormatDuration(standing.Elapsed), model.FormatDuration(standing.Gap), formatChange(previous, id, standing.Rank)))
+		}
+		previous = current
+	}
+
+	return report.String()
+}
+
+func formatChange(previous map[int]int, id, rank int) string {
+	before, ok := previous[id]
+	switch {
+	case !ok:
+		return ""
+	case before == rank:
+		return " (=)"
+	default:
+		return fmt.Sprintf(" (%+d)", before-rank)
+	}
+}
@@ -0,0 +1,174 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"biathlon/model"
+	"biathlon/roster"
+)
+
+const (
+	TeamByNation = "nation"
+	TeamByClub   = "club"
+
+	TeamScoringTime   = "time"
+	TeamScoringPoints = "points"
+)
+
+type TeamOptions struct {
+	// Число лучших спортсменов команды, которые идут в зачёт
+	Size int
+	// Признак команды из заявочного списка: TeamByNation или TeamByClub
+	By string
+	// TeamScoringTime - сумма времени, TeamScoringPoints - сумма очков за места по таблице Points
+	Scoring string
+	Points  []int
+}
+
+type TeamMember struct {
+	CompetitorID int    `json:"competitorId"`
+	Name         string `json:"name,omitempty"`
+	Rank         int    `json:"rank"`
+	TotalTime    string `json:"totalTime"`
+	Points       int    `json:"points,omitempty"`
+}
+
+type TeamStanding struct {
+	Rank      int           `json:"rank"`
+	Team      string        `json:"team"`
+	Complete  bool          `json:"complete"`
+	Time      time.Duration `json:"-"`
+	TotalTime string        `json:"totalTime,omitempty"`
+	Points    int           `json:"points,omitempty"`
+	// Засчитанные спортсмены в порядке мест
+	Members []TeamMember `json:"members"`
+}
+
+// TeamClassification суммирует результаты Size лучших финишировавших спортсменов каждой команды.
+// Полные команды всегда выше неполных, неполные упорядочены по числу засчитанных спортсменов.
+// При равенстве суммы выше команда, чей лучший засчитанный спортсмен занял более высокое место.
+func TeamClassification(results []Result, r roster.Roster, opts TeamOptions) []TeamStanding {
+	teams := make(map[string]*TeamStanding)
+
+	// Results уже упорядочены по местам, поэтому первые Size спортсменов команды - лучшие
+	for _, result := range results {
+		if result.Rank == 0 {
+			continue
+		}
+
+		athlete := r.Athlete(result.CompetitorID)
+		name := athlete.Nation
+		if opts.By == TeamByClub {
+			name = athlete.Club
+		}
+		if name == "" {
+			continue
+		}
+
+		team, exists := teams[name]
+		if !exists {
+			team = &TeamStanding{Team: name}
+			teams[name] = team
+		}
+		if len(team.Members) == opts.Size {
+			continue
+		}
+
+		member := TeamMember{CompetitorID: result.CompetitorID, Name: athlete.Name, Rank: result.Rank, TotalTime: result.TotalTime}
+		if opts.Scoring == TeamScoringPoints {
+			member.Points = PointsFor(opts.Points, result.Rank)
+		}
+		team.Members = append(team.Members, member)
+		team.Time += result.Duration()
+		team.Points += member.Points
+	}
+
+	standings := make([]TeamStanding, 0, len(teams))
+	for _, team := range teams {
+		team.Complete = len(team.Members) == opts.Size
+		if opts.Scoring == TeamScoringPoints {
+			team.TotalTime = ""
+		} else {
+			team.TotalTime = model.FormatDuration(team.Time)
+		}
+		standings = append(standings, *team)
+	}
+
+	better := func(a, b TeamStanding) int {
+		if len(a.Members) != len(b.Members) {
+			return len(b.Members) - len(a.Members)
+		}
+		if opts.Scoring == TeamScoringPoints && a.Points != b.Points {
+			return b.Points - a.Points
+		}
+		if opts.Scoring != TeamScoringPoints && a.Time != b.Time {
+			if a.Time < b.Time {
+				return -1
+			}
+			return 1
+		}
+		return a.Members[0].Rank - b.Members[0].Rank
+	}
+
+	sort.Slice(standings, func(i, j int) bool {
+		if c := better(standings[i], standings[j]); c != 0 {
+			return c < 0
+		}
+		return standings[i].Team < standings[j].Team
+	})
+
+	for i := range standings {
+		standings[i].Rank = i + 1
+		if i > 0 && better(standings[i-1], standings[i]) == 0 {
+			standings[i].Rank = standings[i-1].Rank
+		}
+	}
+	return standings
+}
+
+// GenerateTeamReport выводит командный зачёт: место, команда, сумма и засчитанные спортсмены с их местами.
+// Неполные команды помечаются числом засчитанных спортсменов.
+func GenerateTeamReport(standings []TeamStanding, opts TeamOptions) string {
+	var report strings.Builder
+
+	for _, team := range standings {
+		total := team.TotalTime
+		if opts.Scoring == TeamScoringPoints {
+			total = fmt.Sprint(team.Points)
+		}
+
+		members := make([]string, len(team.Members))
+		for i, m := range team.Members {
+			value := m.TotalTime
+			if opts.Scoring == TeamScoringPoints {
+				value = fmt.Sprint(m.Points)
+			}
+			name := m.Name
+			if name == "" {
+				name = fmt.Sprintf("competitor(%d)", m.CompetitorID)
+			}
+			members[i] = fmt.Sprintf("%d %s %s", m.Rank, name, value)
+		}
+
+		incomplete := ""
+		if !team.Complete {
+			incomplete = fmt.Sprintf(" [%d/%d]", len(team.Members), opts.Size)
+		}
+
+		report.WriteString(fmt.Sprintf("%d. %s %s%s (%s)\n", team.Rank, team.Team, total, incomplete, strings.Join(members, ", ")))
+	}
+
+	return report.String()
+}
+
+func GenerateTeamJSON(standings []TeamStanding) (string, error) {
+	data, err := json.MarshalIndent(standings, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(data) + "\n", nil
+}
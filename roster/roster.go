@@ -0,0 +1,123 @@
+package roster
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Athlete - участник из заявочного списка
+type Athlete struct {
+	ID       int    `json:"competitorId"`
+	Name     string `json:"name,omitempty"`
+	Category string `json:"category,omitempty"`
+	Nation   string `json:"nation,omitempty"`
+	Club     string `json:"club,omitempty"`
+	// Стартовая группа для жеребьёвки
+	Group string `json:"group,omitempty"`
+}
+
+// Key идентифицирует спортсмена между гонками: по имени, а если его нет - по номеру
+func (a Athlete) Key() string {
+	if a.Name != "" {
+		return a.Name
+	}
+	return "#" + strconv.Itoa(a.ID)
+}
+
+// Label - имя спортсмена для отчётов
+func (a Athlete) Label() string {
+	if a.Name != "" {
+		return a.Name
+	}
+	return fmt.Sprintf("competitor(%d)", a.ID)
+}
+
+// Roster - заявочный список по номерам участников
+type Roster map[int]Athlete
+
+// Athlete возвращает спортсмена по номеру; неизвестный номер даёт спортсмена только с номером
+func (r Roster) Athlete(id int) Athlete {
+	if athlete, ok := r[id]; ok {
+		return athlete
+	}
+	return Athlete{ID: id}
+}
+
+// IDs возвращает номера участников по возрастанию
+func (r Roster) IDs() []int {
+	ids := make([]int, 0, len(r))
+	for id := range r {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	return ids
+}
+
+// Parse читает CSV с заголовком. Обязательна колонка id, остальные (name, category, nation, club, group)
+// могут идти в любом порядке или отсутствовать.
+func Parse(r io.Reader) (Roster, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return Roster{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	index := make(map[string]int)
+	for i, name := range header {
+		index[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	if _, ok := index["id"]; !ok {
+		return nil, fmt.Errorf("roster header must contain %q column, got %s", "id", strings.Join(header, ","))
+	}
+
+	roster := make(Roster)
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		field := func(column string) string {
+			if i, ok := index[column]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+
+		id, err := strconv.Atoi(field("id"))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid id %q", line, field("id"))
+		}
+		if _, exists := roster[id]; exists {
+			return nil, fmt.Errorf("line %d: duplicate id %d", line, id)
+		}
+
+		roster[id] = Athlete{ID: id, Name: field("name"), Category: field("category"), Nation: field("nation"), Club: field("club"), Group: field("group")}
+	}
+
+	return roster, nil
+}
+
+func LoadFromFile(path string) (Roster, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return Parse(f)
+}
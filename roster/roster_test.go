@@ -0,0 +1,37 @@
+package roster
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	data := `id, nation, name, category, group
+1, NOR, Johannes Boe, Men, red
+2, FRA, Julia Simon, Women
+3, , ,
+`
+	r, err := Parse(strings.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if a := r.Athlete(2); a.Name != "Julia Simon" || a.Nation != "FRA" || a.Category != "Women" {
+		t.Errorf("Unexpected athlete: %+v", a)
+	}
+	if a := r.Athlete(1); a.Group != "red" {
+		t.Errorf("Unexpected group: %+v", a)
+	}
+	if a := r.Athlete(3); a.Key() != "#3" || a.Label() != "competitor(3)" {
+		t.Errorf("Unexpected unnamed athlete: %+v", a)
+	}
+	if a := r.Athlete(42); a.ID != 42 || a.Name != "" {
+		t.Errorf("Unknown athlete should only have an id, got %+v", a)
+	}
+
+	for _, bad := range []string{"name\nX\n", "id\nabc\n", "id\n1\n1\n"} {
+		if _, err := Parse(strings.NewReader(bad)); err == nil {
+			t.Errorf("Parse(%q): expected error", bad)
+		}
+	}
+}
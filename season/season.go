@@ -0,0 +1,169 @@
+package season
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"biathlon/config"
+	"biathlon/event"
+	"biathlon/race"
+	"biathlon/report"
+	"biathlon/roster"
+)
+
+// Season - серия гонок с общей таблицей очков
+type Season struct {
+	Name string `json:"name"`
+	// Очки за места начиная с первого; по умолчанию report.WorldCupPoints
+	Points []int `json:"points,omitempty"`
+	// Число худших результатов, которые не идут в зачёт
+	DropWorst int `json:"dropWorst,omitempty"`
+	// Заявочный список по умолчанию для всех гонок
+	Roster string      `json:"roster,omitempty"`
+	Races  []RaceEntry `json:"races"`
+
+	dir string
+}
+
+// RaceEntry - гонка сезона. Если config и events не заданы, результаты берутся из хранилища по имени гонки.
+type RaceEntry struct {
+	Name   string `json:"name"`
+	Config string `json:"config,omitempty"`
+	Events string `json:"events,omitempty"`
+	Roster string `json:"roster,omitempty"`
+}
+
+// Load читает описание сезона; пути к файлам гонок считаются относительно него
+func Load(path string) (*Season, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var s Season
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, err
+	}
+	s.dir = filepath.Dir(path)
+
+	if len(s.Points) == 0 {
+		s.Points = report.WorldCupPoints
+	}
+	if errs := s.Validate(); len(errs) > 0 {
+		return nil, fmt.Errorf("invalid season: %v", errs[0])
+	}
+	return &s, nil
+}
+
+func (s *Season) Validate() []error {
+	var errs []error
+
+	if len(s.Races) == 0 {
+		errs = append(errs, fmt.Errorf("season has no races"))
+	}
+	if s.DropWorst < 0 || s.DropWorst >= max(1, len(s.Races)) {
+		errs = append(errs, fmt.Errorf("dropWorst must be between 0 and %d, got %d", max(0, len(s.Races)-1), s.DropWorst))
+	}
+	for i, p := range s.Points {
+		if p < 0 || i > 0 && p > s.Points[i-1] {
+			errs = append(errs, fmt.Errorf("points must be non-negative and non-increasing, got %v", s.Points))
+			break
+		}
+	}
+
+	seen := make(map[string]bool)
+	for i, entry := range s.Races {
+		switch {
+		case entry.Name == "":
+			errs = append(errs, fmt.Errorf("race %d has no name", i+1))
+		case seen[entry.Name]:
+			errs = append(errs, fmt.Errorf("duplicate race %q", entry.Name))
+		case (entry.Config == "") != (entry.Events == ""):
+			errs = append(errs, fmt.Errorf("race %q must have both config and events or neither", entry.Name))
+		}
+		seen[entry.Name] = true
+	}
+
+	return errs
+}
+
+// RaceResults - итоговый протокол одной гонки сезона
+type RaceResults struct {
+	Name    string
+	Results []report.Result
+	Roster  roster.Roster
+}
+
+// ResultsSource возвращает сохранённые результаты гонки по имени
+type ResultsSource func(name string) ([]report.Result, error)
+
+// LoadResults прогоняет события каждой гонки через race.Controller. Гонки без файлов берутся из source,
+// который может быть nil, если все гонки заданы файлами.
+func (s *Season) LoadResults(source ResultsSource) ([]RaceResults, error) {
+	var defaultRoster roster.Roster
+	if s.Roster != "" {
+		var err error
+		if defaultRoster, err = roster.LoadFromFile(s.path(s.Roster)); err != nil {
+			return nil, fmt.Errorf("loading roster: %w", err)
+		}
+	}
+
+	races := make([]RaceResults, 0, len(s.Races))
+	for _, entry := range s.Races {
+		rr := RaceResults{Name: entry.Name, Roster: defaultRoster}
+
+		if entry.Roster != "" {
+			r, err := roster.LoadFromFile(s.path(entry.Roster))
+			if err != nil {
+				return nil, fmt.Errorf("race %q: loading roster: %w", entry.Name, err)
+			}
+			rr.Roster = r
+		}
+
+		var err error
+		if entry.Config != "" {
+			rr.Results, err = s.process(entry)
+		} else if source != nil {
+			rr.Results, err = source(entry.Name)
+		} else {
+			err = fmt.Errorf("no config and events and no results store")
+		}
+		if err != nil {
+			return nil, fmt.Errorf("race %q: %w", entry.Name, err)
+		}
+
+		races = append(races, rr)
+	}
+
+	return races, nil
+}
+
+func (s *Season) process(entry RaceEntry) ([]report.Result, error) {
+	cfg, err := config.LoadFromFile(s.path(entry.Config))
+	if err != nil {
+		return nil, fmt.Errorf("loading configuration: %w", err)
+	}
+	if errs := cfg.Validate(); len(errs) > 0 {
+		return nil, fmt.Errorf("invalid configuration: %v", errs[0])
+	}
+
+	events, err := event.LoadFromFile(s.path(entry.Events))
+	if err != nil {
+		return nil, fmt.Errorf("loading events: %w", err)
+	}
+
+	ctrl := race.NewController(cfg)
+	if _, err := ctrl.ProcessEvents(events); err != nil {
+		return nil, fmt.Errorf("processing events: %w", err)
+	}
+	return ctrl.Results(), nil
+}
+
+func (s *Season) path(p string) string {
+	if filepath.IsAbs(p) || s.dir == "" {
+		return p
+	}
+	return filepath.Join(s.dir, p)
+}
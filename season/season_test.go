@@ -0,0 +1,133 @@
+package season
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"biathlon/report"
+	"biathlon/roster"
+)
+
+var testRoster = roster.Roster{
+	1: {ID: 1, Name: "Anna", Category: "Women"},
+	2: {ID: 2, Name: "Boris", Category: "Men"},
+	3: {ID: 3, Name: "Clara", Category: "Women"},
+	4: {ID: 4, Name: "Dmitry", Category: "Men"},
+}
+
+func finished(rank, id int, total string) report.Result {
+	return report.Result{Rank: rank, CompetitorID: id, Status: "Finished", TotalTime: total}
+}
+
+func TestStandings(t *testing.T) {
+	s := &Season{Name: "Cup", Points: []int{10, 8, 6, 5}, DropWorst: 1}
+
+	races := []RaceResults{
+		{Name: "R1", Roster: testRoster, Results: []report.Result{
+			finished(1, 2, "00:20:00.000"), finished(2, 1, "00:21:00.000"), finished(3, 3, "00:22:00.000"),
+			{CompetitorID: 4, Status: "NotFinished"},
+		}},
+		{Name: "R2", Roster: testRoster, Results: []report.Result{
+			finished(1, 1, "00:20:00.000"), finished(1, 3, "00:20:00.000"), finished(3, 4, "00:21:00.000"),
+		}},
+		{Name: "R3", Roster: testRoster, Results: []report.Result{
+			finished(1, 4, "00:19:00.000"), finished(2, 1, "00:20:00.000"), finished(3, 2, "00:21:00.000"),
+		}},
+	}
+
+	got := s.GenerateReport(races)
+	want := `Cup: R1, R2, R3
+Worst 1 result(s) dropped
+
+Overall
+1. Anna 18 [(8) 10 8]
+2. Boris 16 [10 (-) 6]
+2. Clara 16 [6 10 (-)]
+2. Dmitry 16 [(0) 6 10]
+
+Men
+1. Dmitry 20 [(0) 10 10]
+2. Boris 18 [10 (-) 8]
+
+Women
+1. Anna 20 [(10) 10 10]
+2. Clara 18 [8 10 (-)]
+`
+	if got != want {
+		t.Errorf("GenerateReport() =\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestStandingsPhotoFinish(t *testing.T) {
+	s := &Season{Name: "Cup", Points: []int{10, 8, 6}}
+
+	races := []RaceResults{{Name: "Mass start", Roster: testRoster, Results: []report.Result{
+		finished(1, 1, "00:30:00.000"), finished(2, 3, "00:30:00.000"),
+	}}}
+
+	standings := s.Standings(races)[0].Standings
+	if len(standings) != 2 || standings[0].Total != 10 || standings[1].Total != 8 || standings[1].BestRank != 2 {
+		t.Errorf("Photo finish places must not be shared: %+v", standings)
+	}
+}
+
+func TestLoadResults(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"season.json":        `{"name": "Cup", "roster": "roster.csv", "races": [{"name": "sprint", "config": "sprint/config.json", "events": "sprint/events"}, {"name": "stored"}]}`,
+		"roster.csv":         "id,name\n1,Anna\n",
+		"sprint/config.json": `{"laps": 1, "lapLen": 3000, "penaltyLen": 150, "firingLines": 1, "start": "10:00:00.000", "startDelta": "00:01:00"}`,
+		"sprint/events": `[09:00:00.000] 1 1
+[09:10:00.000] 2 1 10:00:00.000
+[10:00:01.000] 4 1
+[10:08:00.000] 5 1 1
+[10:08:10.000] 6 1 1
+[10:08:11.000] 6 1 2
+[10:08:12.000] 6 1 3
+[10:08:13.000] 6 1 4
+[10:08:14.000] 6 1 5
+[10:08:20.000] 7 1
+[10:12:00.000] 10 1
+`,
+	}
+	for name, data := range files {
+		path := filepath.Join(dir, name)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	s, err := Load(filepath.Join(dir, "season.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(s.Points) != 40 || s.Points[0] != 90 || s.Points[39] != 1 {
+		t.Errorf("Expected World Cup points by default, got %v", s.Points)
+	}
+
+	if _, err := s.LoadResults(nil); err == nil || !strings.Contains(err.Error(), "stored") {
+		t.Errorf("Expected error for stored race without a store, got %v", err)
+	}
+
+	races, err := s.LoadResults(func(name string) ([]report.Result, error) {
+		return []report.Result{finished(1, 1, "00:11:00.000")}, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	standings := s.Standings(races)[0].Standings
+	if len(standings) != 1 || standings[0].Athlete != "Anna" || standings[0].Total != 180 {
+		t.Errorf("Unexpected standings: %+v", standings)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	s := &Season{Points: []int{10, 12}, DropWorst: 3, Races: []RaceEntry{{Name: "a"}, {Name: "a"}, {Name: "b", Config: "c.json"}}}
+	if errs := s.Validate(); len(errs) != 4 {
+		t.Errorf("Expected 4 errors, got %v", errs)
+	}
+}
@@ -0,0 +1,211 @@
+package season
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"biathlon/report"
+)
+
+// Overall - имя общего зачёта без деления на категории
+const Overall = "Overall"
+
+// Standing - место спортсмена в зачёте сезона
+type Standing struct {
+	Rank    int    `json:"rank"`
+	Athlete string `json:"athlete"`
+	Nation  string `json:"nation,omitempty"`
+	Total   int    `json:"total"`
+	// Очки за каждую гонку сезона по порядку; -1 - не участвовал
+	Points []int `json:"points"`
+	// Номера гонок (с 0), результаты которых отброшены как худшие
+	Dropped []int `json:"dropped,omitempty"`
+	// Лучшее место в гонках сезона, используется при равенстве очков
+	BestRank int `json:"bestRank,omitempty"`
+}
+
+// Classification - зачёт сезона: общий или по категории
+type Classification struct {
+	Category  string     `json:"category"`
+	Standings []Standing `json:"standings"`
+}
+
+// Standings вычисляет общий зачёт и зачёты по категориям.
+// В общем зачёте очки начисляются за место в гонке, в зачёте категории - за место среди спортсменов
+// этой категории. Участники с равным временем получают одинаковое место и одинаковые очки.
+func (s *Season) Standings(races []RaceResults) []Classification {
+	classifications := []Classification{{Category: Overall, Standings: s.classify(races, "")}}
+
+	categories := make(map[string]bool)
+	for _, rr := range races {
+		for _, result := range rr.Results {
+			if category := rr.Roster.Athlete(result.CompetitorID).Category; category != "" {
+				categories[category] = true
+			}
+		}
+	}
+
+	names := make([]string, 0, len(categories))
+	for category := range categories {
+		names = append(names, category)
+	}
+	sort.Strings(names)
+
+	for _, category := range names {
+		classifications = append(classifications, Classification{Category: category, Standings: s.classify(races, category)})
+	}
+	return classifications
+}
+
+func (s *Season) classify(races []RaceResults, category string) []Standing {
+	standings := make(map[string]*Standing)
+
+	for i, rr := range races {
+		rank, prevRank, place := 0, 0, 0
+		for _, result := range rr.Results {
+			athlete := rr.Roster.Athlete(result.CompetitorID)
+			if category != "" && athlete.Category != category {
+				continue
+			}
+
+			st, exists := standings[athlete.Key()]
+			if !exists {
+				st = &Standing{Athlete: athlete.Label(), Nation: athlete.Nation, Points: make([]int, len(races))}
+				for j := range st.Points {
+					st.Points[j] = -1
+				}
+				standings[athlete.Key()] = st
+			}
+
+			// Неклассифицированные участники стартовали, но очков не получают
+			if result.Rank == 0 {
+				st.Points[i] = 0
+				continue
+			}
+
+			// Место в категории пересчитывается по местам протокола: равные места остаются равными,
+			// а разведённые фотофинишем при одинаковом времени - нет
+			place++
+			if result.Rank != prevRank {
+				rank = place
+			}
+			prevRank = result.Rank
+
+			st.Points[i] = report.PointsFor(s.Points, rank)
+			if st.BestRank == 0 || rank < st.BestRank {
+				st.BestRank = rank
+			}
+		}
+	}
+
+	result := make([]Standing, 0, len(standings))
+	for _, st := range standings {
+		st.Total, st.Dropped = s.total(st.Points)
+		result = append(result, *st)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		a, b := result[i], result[j]
+		if a.Total != b.Total {
+			return a.Total > b.Total
+		}
+		if a.BestRank != b.BestRank {
+			// Спортсмен без классифицированных результатов уступает любому с результатом
+			return a.BestRank != 0 && (b.BestRank == 0 || a.BestRank < b.BestRank)
+		}
+		return a.Athlete < b.Athlete
+	})
+
+	for i := range result {
+		result[i].Rank = i + 1
+		if i > 0 && result[i].Total == result[i-1].Total && result[i].BestRank == result[i-1].BestRank {
+			result[i].Rank = result[i-1].Rank
+		}
+	}
+	return result
+}
+
+// total суммирует очки без DropWorst худших гонок; пропущенная гонка считается худшим результатом
+func (s *Season) total(points []int) (int, []int) {
+	order := make([]int, len(points))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return points[order[i]] < points[order[j]]
+	})
+
+	dropped := append([]int(nil), order[:min(s.DropWorst, len(order))]...)
+	sort.Ints(dropped)
+
+	total := 0
+	for _, i := range order[len(dropped):] {
+		total += max(0, points[i])
+	}
+	return total, dropped
+}
+
+// GenerateReport возвращает текстовый отчёт по зачётам сезона.
+// Очки отброшенных гонок выводятся в скобках, пропущенные гонки - прочерком.
+func (s *Season) GenerateReport(races []RaceResults) string {
+	var b strings.Builder
+
+	names := make([]string, len(races))
+	for i, rr := range races {
+		names[i] = rr.Name
+	}
+	fmt.Fprintf(&b, "%s: %s\n", s.Name, strings.Join(names, ", "))
+	if s.DropWorst > 0 {
+		fmt.Fprintf(&b, "Worst %d result(s) dropped\n", s.DropWorst)
+	}
+
+	for _, classification := range s.Standings(races) {
+		fmt.Fprintf(&b, "\n%s\n", classification.Category)
+		for _, st := range classification.Standings {
+			fmt.Fprintf(&b, "%d. %s %d [%s]\n", st.Rank, st.Athlete, st.Total, formatPoints(st))
+		}
+	}
+
+	return b.String()
+}
+
+func (s *Season) GenerateJSON(races []RaceResults) (string, error) {
+	names := make([]string, len(races))
+	for i, rr := range races {
+		names[i] = rr.Name
+	}
+
+	data, err := json.MarshalIndent(struct {
+		Name            string           `json:"name"`
+		Races           []string         `json:"races"`
+		Points          []int            `json:"points"`
+		DropWorst       int              `json:"dropWorst"`
+		Classifications []Classification `json:"classifications"`
+	}{s.Name, names, s.Points, s.DropWorst, s.Standings(races)}, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(data) + "\n", nil
+}
+
+func formatPoints(st Standing) string {
+	dropped := make(map[int]bool)
+	for _, i := range st.Dropped {
+		dropped[i] = true
+	}
+
+	parts := make([]string, len(st.Points))
+	for i, p := range st.Points {
+		value := "-"
+		if p >= 0 {
+			value = fmt.Sprint(p)
+		}
+		if dropped[i] {
+			value = "(" + value + ")"
+		}
+		parts[i] = value
+	}
+	return strings.Join(parts, " ")
+}
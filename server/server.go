@@ -0,0 +1,276 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+
+	"biathlon/config"
+	"biathlon/event"
+	"biathlon/race"
+	"biathlon/store"
+)
+
+// Server обслуживает HTTP API:
+//
+//	GET  /races                      список гонок
+//	PUT  /races/{name}               создать гонку или заменить её конфигурацию (тело - config.json)
+//	GET  /races/{name}               конфигурация и число событий
+//	POST /races/{name}/events        добавить события в любом поддерживаемом формате
+//	GET  /races/{name}/log           выходной лог (?format=text|jsonl)
+//	GET  /races/{name}/report        итоговый отчёт (?format=text|json)
+//
+// Гонки хранятся в store.Store. После каждого изменения результаты пересчитываются по всем событиям
+// и сохраняются, поэтому порядок загрузки не важен, а перезапуск сервера с файловым хранилищем не теряет данные.
+type Server struct {
+	// mu упорядочивает изменения, чтобы результаты не перезаписывались устаревшими
+	mu    sync.Mutex
+	store store.Store
+}
+
+func New(st store.Store) *Server {
+	return &Server{store: st}
+}
+
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /races", s.listRaces)
+	mux.HandleFunc("PUT /races/{name}", s.putRace)
+	mux.HandleFunc("GET /races/{name}", s.getRace)
+	mux.HandleFunc("POST /races/{name}/events", s.postEvents)
+	mux.HandleFunc("GET /races/{name}/log", s.getLog)
+	mux.HandleFunc("GET /races/{name}/report", s.getReport)
+	return mux
+}
+
+func (s *Server) listRaces(w http.ResponseWriter, r *http.Request) {
+	names, err := s.store.Races()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, names)
+}
+
+func (s *Server) putRace(w http.ResponseWriter, r *http.Request) {
+	data, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	cfg, err := config.Parse(data)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid config: %w", err))
+		return
+	}
+	if errs := cfg.Validate(); len(errs) > 0 {
+		writeErrors(w, http.StatusUnprocessableEntity, errs)
+		return
+	}
+
+	name := r.PathValue("name")
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	status := http.StatusOK
+	if _, err := s.store.Race(name); errors.Is(err, store.ErrNotFound) {
+		status = http.StatusCreated
+	}
+
+	if err := s.store.PutConfig(name, cfg); err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if err := s.recompute(name); err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	writeJSON(w, status, map[string]string{"name": name})
+}
+
+func (s *Server) getRace(w http.ResponseWriter, r *http.Request) {
+	rc, ok := s.race(w, r)
+	if !ok {
+		return
+	}
+
+	writeJSON(w, http.StatusOK, struct {
+		Name   string         `json:"name"`
+		Config *config.Config `json:"config"`
+		Events int            `json:"events"`
+	}{rc.Name, rc.Config, len(rc.Events)})
+}
+
+func (s *Server) postEvents(w http.ResponseWriter, r *http.Request) {
+	data, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	// Пакет принимается целиком или не принимается вовсе
+	records, parseErrs := event.DecodeRecords(event.DetectFormat("", data), data)
+	if len(parseErrs) > 0 {
+		errs := make([]error, len(parseErrs))
+		for i, err := range parseErrs {
+			errs[i] = err
+		}
+		writeErrors(w, http.StatusBadRequest, errs)
+		return
+	}
+
+	events := make([]event.Event, len(records))
+	for i, record := range records {
+		events[i] = record.Event
+	}
+
+	name := r.PathValue("name")
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.store.AppendEvents(name, events); err != nil {
+		writeStoreError(w, name, err)
+		return
+	}
+	if err := s.recompute(name); err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	rc, err := s.store.Race(name)
+	if err != nil {
+		writeStoreError(w, name, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]int{"accepted": len(events), "events": len(rc.Events)})
+}
+
+func (s *Server) getLog(w http.ResponseWriter, r *http.Request) {
+	ctrl, ok := s.controller(w, r)
+	if !ok {
+		return
+	}
+
+	switch format := r.URL.Query().Get("format"); format {
+	case "", "text":
+		writeText(w, ctrl.OutputLog)
+	case "jsonl":
+		log, err := ctrl.JSONLog()
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		io.WriteString(w, log)
+	default:
+		writeError(w, http.StatusBadRequest, fmt.Errorf("unknown log format %q", format))
+	}
+}
+
+func (s *Server) getReport(w http.ResponseWriter, r *http.Request) {
+	switch format := r.URL.Query().Get("format"); format {
+	case "", "text":
+		ctrl, ok := s.controller(w, r)
+		if !ok {
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		io.WriteString(w, ctrl.GenerateReport())
+	case "json":
+		// Сохранённые результаты не требуют повторного прогона событий
+		rc, ok := s.race(w, r)
+		if !ok {
+			return
+		}
+		writeJSON(w, http.StatusOK, rc.Results)
+	default:
+		writeError(w, http.StatusBadRequest, fmt.Errorf("unknown report format %q", format))
+	}
+}
+
+func (s *Server) race(w http.ResponseWriter, r *http.Request) (*store.Race, bool) {
+	name := r.PathValue("name")
+	rc, err := s.store.Race(name)
+	if err != nil {
+		writeStoreError(w, name, err)
+		return nil, false
+	}
+	return rc, true
+}
+
+// controller прогоняет все события гонки через новый контроллер
+func (s *Server) controller(w http.ResponseWriter, r *http.Request) (*race.Controller, bool) {
+	rc, ok := s.race(w, r)
+	if !ok {
+		return nil, false
+	}
+
+	ctrl, err := process(rc)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return nil, false
+	}
+	return ctrl, true
+}
+
+// recompute пересчитывает и сохраняет результаты гонки; вызывается под s.mu
+func (s *Server) recompute(name string) error {
+	rc, err := s.store.Race(name)
+	if err != nil {
+		return err
+	}
+
+	ctrl, err := process(rc)
+	if err != nil {
+		return err
+	}
+	return s.store.PutResults(name, ctrl.Competitors, ctrl.Results())
+}
+
+func process(rc *store.Race) (*race.Controller, error) {
+	ctrl := race.NewController(rc.Config)
+	if _, err := ctrl.ProcessEvents(rc.Events); err != nil {
+		return nil, err
+	}
+	return ctrl, nil
+}
+
+func writeStoreError(w http.ResponseWriter, name string, err error) {
+	if errors.Is(err, store.ErrNotFound) {
+		writeError(w, http.StatusNotFound, fmt.Errorf("race %q not found", name))
+		return
+	}
+	writeError(w, http.StatusInternalServerError, err)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeText(w http.ResponseWriter, lines []string) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	for _, line := range lines {
+		io.WriteString(w, line+"\n")
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeErrors(w, status, []error{err})
+}
+
+func writeErrors(w http.ResponseWriter, status int, errs []error) {
+	messages := make([]string, len(errs))
+	for i, err := range errs {
+		messages[i] = err.Error()
+	}
+	writeJSON(w, status, map[string][]string{"errors": messages})
+}
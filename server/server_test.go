@@ -0,0 +1,136 @@
+package server
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"biathlon/store"
+)
+
+const testConfig = `{"laps": 1, "lapLen": 3000, "penaltyLen": 150, "firingLines": 1, "start": "10:00:00.000", "startDelta": "00:01:00"}`
+
+func do(t *testing.T, srv *httptest.Server, method, path, body string) (int, string) {
+	t.Helper()
+
+	req, err := http.NewRequest(method, srv.URL+path, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp.StatusCode, string(data)
+}
+
+func TestRaceLifecycle(t *testing.T) {
+	srv := httptest.NewServer(New(store.NewMemoryStore()).Handler())
+	defer srv.Close()
+
+	if status, _ := do(t, srv, http.MethodPut, "/races/sprint", testConfig); status != http.StatusCreated {
+		t.Fatalf("PUT race: status %d", status)
+	}
+
+	events := `[09:00:00.000] 1 1
+[09:10:00.000] 2 1 10:00:00.000
+[10:00:01.000] 4 1
+[10:08:00.000] 5 1 1
+[10:08:10.000] 6 1 1
+[10:08:11.000] 6 1 2
+[10:08:12.000] 6 1 3
+[10:08:13.000] 6 1 4
+[10:08:14.000] 6 1 5
+[10:08:20.000] 7 1
+[10:12:00.000] 10 1
+`
+	if status, body := do(t, srv, http.MethodPost, "/races/sprint/events", events); status != http.StatusOK {
+		t.Fatalf("POST events: status %d: %s", status, body)
+	}
+
+	status, body := do(t, srv, http.MethodGet, "/races/sprint/report", "")
+	if status != http.StatusOK || !strings.HasPrefix(body, "[00:12:00.000] 1") {
+		t.Errorf("GET report: status %d:\n%s", status, body)
+	}
+
+	status, body = do(t, srv, http.MethodGet, "/races/sprint/report?format=json", "")
+	var results []struct {
+		Rank         int    `json:"rank"`
+		CompetitorID int    `json:"competitorId"`
+		Status       string `json:"status"`
+	}
+	if err := json.Unmarshal([]byte(body), &results); err != nil || status != http.StatusOK {
+		t.Fatalf("GET json report: status %d, %v:\n%s", status, err, body)
+	}
+	if len(results) != 1 || results[0].Rank != 1 || results[0].Status != "Finished" {
+		t.Errorf("Unexpected results: %+v", results)
+	}
+
+	status, body = do(t, srv, http.MethodGet, "/races/sprint/log", "")
+	if status != http.StatusOK || !strings.Contains(body, "The competitor(1) has finished") {
+		t.Errorf("GET log: status %d:\n%s", status, body)
+	}
+}
+
+func TestErrors(t *testing.T) {
+	srv := httptest.NewServer(New(store.NewMemoryStore()).Handler())
+	defer srv.Close()
+
+	tests := []struct {
+		method, path, body string
+		want               int
+	}{
+		{http.MethodGet, "/races/missing/report", "", http.StatusNotFound},
+		{http.MethodPut, "/races/sprint", `{"laps": 0}`, http.StatusUnprocessableEntity},
+		{http.MethodPut, "/races/sprint", `not json`, http.StatusBadRequest},
+		{http.MethodPost, "/races/missing/events", "[09:00:00.000] 1 1\n", http.StatusNotFound},
+		{http.MethodPost, "/races/missing/events", "[09:00:00.000] 1\n", http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		if status, body := do(t, srv, tt.method, tt.path, tt.body); status != tt.want {
+			t.Errorf("%s %s: status %d, want %d: %s", tt.method, tt.path, status, tt.want, body)
+		}
+	}
+}
+
+func TestRestart(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "races.jsonl")
+
+	st, err := store.OpenFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(New(st).Handler())
+	do(t, srv, http.MethodPut, "/races/sprint", testConfig)
+	do(t, srv, http.MethodPost, "/races/sprint/events", "[09:00:00.000] 1 1\n[09:10:00.000] 2 1 10:00:00.000\n")
+	srv.Close()
+	st.Close()
+
+	st, err = store.OpenFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer st.Close()
+	srv = httptest.NewServer(New(st).Handler())
+	defer srv.Close()
+
+	status, body := do(t, srv, http.MethodGet, "/races/sprint/report", "")
+	if status != http.StatusOK || body != "[NotStarted] 1 [{,}] {,} 0/0\n" {
+		t.Errorf("GET report after restart: status %d:\n%q", status, body)
+	}
+	status, body = do(t, srv, http.MethodGet, "/races/sprint/report?format=json", "")
+	if status != http.StatusOK || !strings.Contains(body, `"status":"NotStarted"`) {
+		t.Errorf("GET json report after restart: status %d:\n%s", status, body)
+	}
+}
@@ -0,0 +1,241 @@
+package simulate
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"time"
+
+	"biathlon/config"
+	"biathlon/event"
+	"biathlon/model"
+)
+
+type Options struct {
+	Competitors int
+	Seed        int64
+	// Средняя скорость на дистанции и её разброс между участниками, м/с
+	MeanSpeed   float64
+	SpeedStdDev float64
+	// Вероятность попадания одним выстрелом
+	HitProbability float64
+	// Вероятности схода с дистанции и неявки на старт
+	DNFProbability    float64
+	NoShowProbability float64
+}
+
+func DefaultOptions() Options {
+	return Options{
+		Competitors:       30,
+		Seed:              1,
+		MeanSpeed:         4.5,
+		SpeedStdDev:       0.3,
+		HitProbability:    0.85,
+		DNFProbability:    0.05,
+		NoShowProbability: 0.03,
+	}
+}
+
+var dnfComments = []string{
+	"Lost in the forest",
+	"Broken ski",
+	"Broken pole",
+	"Injury",
+	"Equipment failure",
+}
+
+// Доля круга от старта круга до огневого рубежа, если геометрия трассы не задана
+const defaultRangePosition = 0.7
+
+// Generate строит полный поток входящих событий гонки. Результат детерминирован для заданного Seed.
+func Generate(cfg *config.Config, opts Options) ([]event.Event, error) {
+	if errs := cfg.Validate(); len(errs) > 0 {
+		return nil, fmt.Errorf("invalid config: %w", errors.Join(errs...))
+	}
+	if opts.Competitors < 0 {
+		return nil, fmt.Errorf("competitors must not be negative, got %d", opts.Competitors)
+	}
+
+	start, _ := config.ParseClock(cfg.Start)
+	startDelta, _ := cfg.StartDeltaDuration()
+
+	g := &generator{
+		cfg:        cfg,
+		opts:       opts,
+		rng:        rand.New(rand.NewSource(opts.Seed)),
+		start:      start,
+		startDelta: startDelta,
+	}
+
+	order := g.rng.Perm(opts.Competitors)
+	for i := 0; i < opts.Competitors; i++ {
+		g.competitor(i+1, order[i])
+	}
+
+	// Время событий - только время суток, поэтому гонка, выходящая за полночь, не может быть записана
+	sort.SliceStable(g.events, func(i, j int) bool {
+		return g.events[i].at.Before(g.events[j].at)
+	})
+	if n := len(g.events); n > 0 {
+		first, last := g.events[0].at, g.events[n-1].at
+		if !sameDay(first, start) || !sameDay(last, start) {
+			return nil, fmt.Errorf("simulated race from %s to %s crosses midnight", first.Format(model.TimeFormat), last.Format(model.TimeFormat))
+		}
+	}
+
+	if cfg.IsMassStart() {
+		g.assignLanes()
+	}
+
+	events := make([]event.Event, len(g.events))
+	for i, e := range g.events {
+		events[i] = e.Event
+	}
+	return events, nil
+}
+
+// assignLanes назначает установки по правилам масс-старта: на первом рубеже по номеру, дальше по порядку прихода
+func (g *generator) assignLanes() {
+	stages := make(map[int]int)
+	arrivals := make(map[int]int)
+	for i := range g.events {
+		e := &g.events[i]
+		if e.EventID != event.OnFiringRange {
+			continue
+		}
+
+		stages[e.CompetitorID]++
+		stage := stages[e.CompetitorID]
+		arrivals[stage]++
+
+		lane := e.CompetitorID
+		if stage > 1 {
+			lane = arrivals[stage]
+		}
+		if g.cfg.RangeLanes > 0 {
+			lane = (lane-1)%g.cfg.RangeLanes + 1
+		}
+		e.ExtraParams = fmt.Sprint(lane)
+	}
+}
+
+func sameDay(a, b time.Time) bool {
+	y1, m1, d1 := a.Date()
+	y2, m2, d2 := b.Date()
+	return y1 == y2 && m1 == m2 && d1 == d2
+}
+
+type generator struct {
+	cfg        *config.Config
+	opts       Options
+	rng        *rand.Rand
+	start      time.Time
+	startDelta time.Duration
+	events     []timedEvent
+}
+
+type timedEvent struct {
+	event.Event
+	at time.Time
+}
+
+func (g *generator) emit(t time.Time, eventID, competitorID int, extraParams string) {
+	t = t.Truncate(time.Millisecond)
+	g.events = append(g.events, timedEvent{at: t, Event: event.Event{
+		Time:         t.Format(model.TimeFormat),
+		EventID:      eventID,
+		CompetitorID: competitorID,
+		ExtraParams:  extraParams,
+	}})
+}
+
+// between возвращает случайную длительность из интервала [min, max)
+func (g *generator) between(min, max time.Duration) time.Duration {
+	return min + time.Duration(g.rng.Int63n(int64(max-min)))
+}
+
+func (g *generator) competitor(id, position int) {
+	// В масс-старте все стартуют одновременно, и жеребьёвка времени старта не проводится
+	planned := g.start
+	g.emit(g.start.Add(-g.between(30*time.Minute, 60*time.Minute)), event.Registered, id, "")
+	if !g.cfg.IsMassStart() {
+		planned = g.start.Add(time.Duration(position) * g.startDelta)
+		g.emit(g.start.Add(-g.between(10*time.Minute, 25*time.Minute)), event.StartTimeSet, id, planned.Format(model.TimeFormat))
+	}
+
+	if g.rng.Float64() < g.opts.NoShowProbability {
+		return
+	}
+
+	g.emit(planned.Add(-g.between(10*time.Second, 60*time.Second)), event.OnStartLine, id, "")
+
+	now := planned
+	if jitter := min(2*time.Second, g.startDelta); jitter > 0 {
+		now = now.Add(g.between(0, jitter))
+	}
+	g.emit(now, event.Started, id, "")
+
+	speed := math.Max(1, g.opts.MeanSpeed+g.rng.NormFloat64()*g.opts.SpeedStdDev)
+	ski := func(distance float64) time.Duration {
+		lapSpeed := math.Max(1, speed*(1+g.rng.NormFloat64()*0.02))
+		return time.Duration(distance / lapSpeed * float64(time.Second))
+	}
+
+	dnfLap := 0
+	if g.rng.Float64() < g.opts.DNFProbability {
+		dnfLap = 1 + g.rng.Intn(max(1, g.cfg.Laps))
+	}
+
+	shooting := 0
+	for lap := 1; lap <= g.cfg.Laps; lap++ {
+		lapLen := float64(g.cfg.LapLength(lap))
+
+		if lap == dnfLap {
+			now = now.Add(ski(lapLen * g.rng.Float64()))
+			g.emit(now, event.CannotContinue, id, dnfComments[g.rng.Intn(len(dnfComments))])
+			return
+		}
+
+		if !g.cfg.HasShooting(lap) {
+			now = now.Add(ski(lapLen))
+			g.emit(now, event.EndedLap, id, "")
+			continue
+		}
+
+		rangeEntrance, afterRange := lapLen*defaultRangePosition, lapLen*(1-defaultRangePosition)
+		if g.cfg.Course != nil {
+			rangeEntrance = float64(g.cfg.Course.RangeEntrance)
+			afterRange = float64(g.cfg.CourseFinish(lap) - g.cfg.RangeExit())
+		}
+
+		shooting++
+		now = now.Add(ski(rangeEntrance))
+		g.emit(now, event.OnFiringRange, id, fmt.Sprint(shooting))
+
+		misses := 0
+		now = now.Add(g.between(10*time.Second, 20*time.Second))
+		for target := 1; target <= 5; target++ {
+			now = now.Add(g.between(2*time.Second, 5*time.Second))
+			if g.rng.Float64() < g.opts.HitProbability {
+				g.emit(now, event.TargetHit, id, fmt.Sprint(target))
+			} else {
+				misses++
+			}
+		}
+
+		now = now.Add(g.between(2*time.Second, 6*time.Second))
+		g.emit(now, event.LeftFiringRange, id, "")
+
+		if misses > 0 {
+			now = now.Add(g.between(3*time.Second, 10*time.Second))
+			g.emit(now, event.EnteredPenalty, id, "")
+			now = now.Add(ski(float64(misses * g.cfg.PenaltyLen)))
+			g.emit(now, event.LeftPenalty, id, "")
+		}
+
+		now = now.Add(ski(afterRange))
+		g.emit(now, event.EndedLap, id, "")
+	}
+}
@@ -0,0 +1,124 @@
+package simulate
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"biathlon/config"
+	"biathlon/event"
+	"biathlon/lint"
+	"biathlon/race"
+)
+
+var testConfig = &config.Config{
+	Laps:        3,
+	LapLen:      3000,
+	PenaltyLen:  150,
+	FiringLines: 2,
+	Start:       "10:00:00.000",
+	StartDelta:  "00:00:30",
+}
+
+func TestGenerateDeterministic(t *testing.T) {
+	opts := DefaultOptions()
+
+	first, err := Generate(testConfig, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, _ := Generate(testConfig, opts)
+	if !reflect.DeepEqual(first, second) {
+		t.Error("Same seed produced different events")
+	}
+
+	opts.Seed++
+	third, _ := Generate(testConfig, opts)
+	if reflect.DeepEqual(first, third) {
+		t.Error("Different seeds produced identical events")
+	}
+}
+
+func TestGeneratePassesValidation(t *testing.T) {
+	opts := DefaultOptions()
+	opts.Competitors = 100
+
+	events, err := Generate(testConfig, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf []byte
+	for _, evt := range events {
+		buf = append(buf, evt.String()+"\n"...)
+	}
+
+	if issues := lint.Lint("config.json", testConfig, "events", buf); lint.HasErrors(issues) {
+		t.Errorf("Generated events have problems: %v", issues)
+	}
+
+	registered := 0
+	for _, evt := range events {
+		if evt.EventID == event.Registered {
+			registered++
+		}
+	}
+	if registered != opts.Competitors {
+		t.Errorf("Expected %d registrations, got %d", opts.Competitors, registered)
+	}
+}
+
+func TestGenerateMassStart(t *testing.T) {
+	cfg := &config.Config{Laps: 2, LapLen: 3000, PenaltyLen: 150, FiringLines: 2, Start: "10:00:00.000", Mode: config.ModeMassStart, RangeLanes: 4}
+	opts := DefaultOptions()
+	opts.Competitors = 10
+
+	events, err := Generate(cfg, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, evt := range events {
+		switch {
+		case evt.EventID == event.StartTimeSet:
+			t.Errorf("Unexpected start time draw in mass start: %s", evt)
+		case evt.EventID == event.Started && evt.Time != "10:00:00.000":
+			t.Errorf("Mass start competitor started at %s", evt.Time)
+		}
+	}
+
+	var buf []byte
+	for _, evt := range events {
+		buf = append(buf, evt.String()+"\n"...)
+	}
+	if issues := lint.Lint("config.json", cfg, "events", buf); len(issues) != 0 {
+		t.Errorf("Generated events have problems: %v", issues)
+	}
+}
+
+func TestGenerateRejectsMidnight(t *testing.T) {
+	for _, start := range []string{"00:10:00.000", "23:50:00.000"} {
+		cfg := *testConfig
+		cfg.Start = start
+		if _, err := Generate(&cfg, DefaultOptions()); err == nil || !strings.Contains(err.Error(), "midnight") {
+			t.Errorf("Start %s: expected midnight error, got %v", start, err)
+		}
+	}
+}
+
+func BenchmarkProcessEvents(b *testing.B) {
+	opts := DefaultOptions()
+	opts.Competitors = 1000
+
+	events, err := Generate(testConfig, opts)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		ctrl := race.NewController(testConfig)
+		ctrl.ProcessEvents(events)
+		ctrl.GenerateReport()
+	}
+}
@@ -0,0 +1,204 @@
+package store
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"biathlon/config"
+	"biathlon/event"
+	"biathlon/model"
+	"biathlon/report"
+)
+
+const (
+	opRace    = "race"
+	opConfig  = "config"
+	opEvents  = "events"
+	opResults = "results"
+)
+
+// record - одна запись журнала
+type record struct {
+	Op          string                    `json:"op"`
+	Race        string                    `json:"race"`
+	Config      *config.Config            `json:"config,omitempty"`
+	Events      []event.Event             `json:"events,omitempty"`
+	Competitors map[int]*model.Competitor `json:"competitors,omitempty"`
+	Results     []report.Result           `json:"results,omitempty"`
+}
+
+// FileStore - хранилище в виде журнала JSON Lines, в который только дописываются записи.
+// При открытии журнал воспроизводится в память и сжимается до одной записи на гонку.
+type FileStore struct {
+	MemoryStore
+	path string
+	file *os.File
+}
+
+func OpenFile(path string) (*FileStore, error) {
+	s := &FileStore{MemoryStore: MemoryStore{races: make(map[string]*Race)}, path: path}
+
+	if err := s.replay(); err != nil {
+		return nil, err
+	}
+	if err := s.compact(); err != nil {
+		return nil, fmt.Errorf("compacting %s: %w", path, err)
+	}
+
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, err
+	}
+	s.file = file
+	return s, nil
+}
+
+func (s *FileStore) PutRace(race *Race) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rec := record{Op: opRace, Race: race.Name, Config: race.Config, Events: race.Events, Competitors: race.Competitors, Results: race.Results}
+	if err := s.append(rec); err != nil {
+		return err
+	}
+	s.putRace(race)
+	return nil
+}
+
+func (s *FileStore) PutConfig(name string, cfg *config.Config) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.append(record{Op: opConfig, Race: name, Config: cfg}); err != nil {
+		return err
+	}
+	s.putConfig(name, cfg)
+	return nil
+}
+
+func (s *FileStore) AppendEvents(name string, events []event.Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.races[name]; !exists {
+		return ErrNotFound
+	}
+	if err := s.append(record{Op: opEvents, Race: name, Events: events}); err != nil {
+		return err
+	}
+	return s.appendEvents(name, events)
+}
+
+func (s *FileStore) PutResults(name string, competitors map[int]*model.Competitor, results []report.Result) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.races[name]; !exists {
+		return ErrNotFound
+	}
+	if err := s.append(record{Op: opResults, Race: name, Competitors: competitors, Results: results}); err != nil {
+		return err
+	}
+	return s.putResults(name, competitors, results)
+}
+
+func (s *FileStore) Close() error {
+	return s.file.Close()
+}
+
+// append дописывает запись в журнал и дожидается её сохранения на диск
+func (s *FileStore) append(rec record) error {
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	if _, err := s.file.Write(append(data, '\n')); err != nil {
+		return err
+	}
+	return s.file.Sync()
+}
+
+func (s *FileStore) replay() error {
+	data, err := os.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	lines := bytes.Split(data, []byte("\n"))
+	for i, line := range lines {
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+
+		var rec record
+		if err := json.Unmarshal(line, &rec); err != nil {
+			// Последняя строка могла не дописаться при аварийном завершении
+			if i == len(lines)-1 {
+				break
+			}
+			return fmt.Errorf("%s:%d: %w", s.path, i+1, err)
+		}
+
+		switch rec.Op {
+		case opRace:
+			s.putRace(&Race{Name: rec.Race, Config: rec.Config, Events: rec.Events, Competitors: rec.Competitors, Results: rec.Results})
+		case opConfig:
+			s.putConfig(rec.Race, rec.Config)
+		case opEvents:
+			err = s.appendEvents(rec.Race, rec.Events)
+		case opResults:
+			err = s.putResults(rec.Race, rec.Competitors, rec.Results)
+		default:
+			err = fmt.Errorf("unknown operation %q", rec.Op)
+		}
+		if err != nil {
+			return fmt.Errorf("%s:%d: %w", s.path, i+1, err)
+		}
+	}
+	return nil
+}
+
+// compact переписывает журнал по текущему состоянию через временный файл и переименование
+func (s *FileStore) compact() error {
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	w := bufio.NewWriter(tmp)
+	names, _ := s.MemoryStore.Races()
+	for _, name := range names {
+		r := s.races[name]
+		data, err := json.Marshal(record{Op: opRace, Race: name, Config: r.Config, Events: r.Events, Competitors: r.Competitors, Results: r.Results})
+		if err != nil {
+			tmp.Close()
+			return err
+		}
+		w.Write(append(data, '\n'))
+	}
+
+	if err := w.Flush(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(0644); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), s.path)
+}
@@ -0,0 +1,146 @@
+package store
+
+import (
+	"errors"
+	"sort"
+	"sync"
+
+	"biathlon/config"
+	"biathlon/event"
+	"biathlon/model"
+	"biathlon/report"
+)
+
+var ErrNotFound = errors.New("race not found")
+
+// Race - сохранённая гонка: конфигурация, входящие события и последние вычисленные результаты
+type Race struct {
+	Name        string                    `json:"name"`
+	Config      *config.Config            `json:"config"`
+	Events      []event.Event             `json:"events"`
+	Competitors map[int]*model.Competitor `json:"competitors,omitempty"`
+	Results     []report.Result           `json:"results,omitempty"`
+}
+
+// Store хранит гонки. Результаты не пересчитываются самим хранилищем: их сохраняет тот,
+// кто прогоняет события через race.Controller.
+type Store interface {
+	// PutRace создаёт или целиком заменяет гонку
+	PutRace(race *Race) error
+	// PutConfig создаёт гонку или заменяет её конфигурацию
+	PutConfig(race string, cfg *config.Config) error
+	// AppendEvents дописывает события к существующей гонке
+	AppendEvents(race string, events []event.Event) error
+	// PutResults заменяет сохранённых участников и результаты гонки
+	PutResults(race string, competitors map[int]*model.Competitor, results []report.Result) error
+	// Race возвращает копию гонки или ErrNotFound
+	Race(name string) (*Race, error)
+	// Races возвращает имена всех гонок по алфавиту
+	Races() ([]string, error)
+	Close() error
+}
+
+// MemoryStore хранит гонки в памяти до завершения процесса
+type MemoryStore struct {
+	mu    sync.Mutex
+	races map[string]*Race
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{races: make(map[string]*Race)}
+}
+
+func (s *MemoryStore) PutRace(race *Race) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.putRace(race)
+	return nil
+}
+
+func (s *MemoryStore) PutConfig(name string, cfg *config.Config) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.putConfig(name, cfg)
+	return nil
+}
+
+func (s *MemoryStore) AppendEvents(name string, events []event.Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.appendEvents(name, events)
+}
+
+func (s *MemoryStore) PutResults(name string, competitors map[int]*model.Competitor, results []report.Result) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.putResults(name, competitors, results)
+}
+
+func (s *MemoryStore) Race(name string) (*Race, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	r, exists := s.races[name]
+	if !exists {
+		return nil, ErrNotFound
+	}
+
+	race := *r
+	race.Events = append([]event.Event(nil), r.Events...)
+	race.Results = append([]report.Result(nil), r.Results...)
+	return &race, nil
+}
+
+func (s *MemoryStore) Races() ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	names := make([]string, 0, len(s.races))
+	for name := range s.races {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+func (s *MemoryStore) Close() error {
+	return nil
+}
+
+// Методы без блокировки используются и FileStore при восстановлении журнала
+
+func (s *MemoryStore) putRace(race *Race) {
+	r := *race
+	r.Events = append([]event.Event(nil), race.Events...)
+	s.races[race.Name] = &r
+}
+
+func (s *MemoryStore) putConfig(name string, cfg *config.Config) {
+	if r, exists := s.races[name]; exists {
+		r.Config = cfg
+		return
+	}
+	s.races[name] = &Race{Name: name, Config: cfg}
+}
+
+func (s *MemoryStore) appendEvents(name string, events []event.Event) error {
+	r, exists := s.races[name]
+	if !exists {
+		return ErrNotFound
+	}
+	r.Events = append(r.Events, events...)
+	return nil
+}
+
+func (s *MemoryStore) putResults(name string, competitors map[int]*model.Competitor, results []report.Result) error {
+	r, exists := s.races[name]
+	if !exists {
+		return ErrNotFound
+	}
+	r.Competitors, r.Results = competitors, results
+	return nil
+}
@@ -0,0 +1,107 @@
+package store
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"biathlon/config"
+	"biathlon/event"
+	"biathlon/model"
+	"biathlon/report"
+)
+
+var testConfig = &config.Config{Laps: 2, LapLen: 3000, PenaltyLen: 150, FiringLines: 2, Start: "10:00:00.000", StartDelta: "00:01:00"}
+
+func fill(t *testing.T, st Store) {
+	t.Helper()
+
+	if err := st.PutConfig("sprint", testConfig); err != nil {
+		t.Fatal(err)
+	}
+	if err := st.AppendEvents("sprint", []event.Event{{Time: "09:00:00.000", EventID: event.Registered, CompetitorID: 1}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := st.AppendEvents("sprint", []event.Event{{Time: "09:10:00.000", EventID: event.StartTimeSet, CompetitorID: 1, ExtraParams: "10:00:00.000"}}); err != nil {
+		t.Fatal(err)
+	}
+	competitors := map[int]*model.Competitor{1: model.NewCompetitor(1, "09:00:00.000", 2)}
+	results := []report.Result{{CompetitorID: 1, Status: model.StatusNotStarted}}
+	if err := st.PutResults("sprint", competitors, results); err != nil {
+		t.Fatal(err)
+	}
+	if err := st.PutRace(&Race{Name: "relay", Config: testConfig}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func check(t *testing.T, st Store) {
+	t.Helper()
+
+	names, err := st.Races()
+	if err != nil || !reflect.DeepEqual(names, []string{"relay", "sprint"}) {
+		t.Fatalf("Races() = %v, %v", names, err)
+	}
+
+	race, err := st.Race("sprint")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if race.Config.Laps != 2 || len(race.Events) != 2 || race.Events[1].ExtraParams != "10:00:00.000" {
+		t.Errorf("Unexpected race: %+v", race)
+	}
+	if len(race.Results) != 1 || race.Results[0].Status != model.StatusNotStarted || race.Competitors[1].RegisterTime != "09:00:00.000" {
+		t.Errorf("Unexpected results: %+v %+v", race.Results, race.Competitors)
+	}
+
+	if _, err := st.Race("missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Race(missing) error = %v, want ErrNotFound", err)
+	}
+	if err := st.AppendEvents("missing", nil); !errors.Is(err, ErrNotFound) {
+		t.Errorf("AppendEvents(missing) error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestMemoryStore(t *testing.T) {
+	st := NewMemoryStore()
+	fill(t, st)
+	check(t, st)
+}
+
+func TestFileStoreReopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "races.jsonl")
+
+	st, err := OpenFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fill(t, st)
+	st.Close()
+
+	// Незаконченная запись в конце журнала после аварийного завершения пропускается
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString(`{"op":"events","race":"sprint","events":[{"ti`)
+	f.Close()
+
+	st, err = OpenFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer st.Close()
+	check(t, st)
+
+	// После открытия журнал сжат до одной записи на гонку
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lines := bytes.Count(data, []byte("\n")); lines != 2 {
+		t.Errorf("Expected 2 records after compaction, got %d", lines)
+	}
+}
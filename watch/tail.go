@@ -0,0 +1,139 @@
+package watch
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+)
+
+// headSize - сколько байт начала файла запоминается, чтобы заметить его перезапись
+const headSize = 64
+
+// Tailer читает из растущего файла только дописанные с прошлого раза полные строки
+type Tailer struct {
+	path    string
+	file    *os.File
+	info    os.FileInfo
+	offset  int64
+	head    []byte
+	partial []byte
+}
+
+func NewTailer(path string) *Tailer {
+	return &Tailer{path: path}
+}
+
+// Poll возвращает новые полные строки. Незавершённая строка в конце файла ждёт следующего вызова.
+//
+// Если файл усечён (перезаписан с начала), reset = true, и строки читаются заново с начала файла.
+// Перезапись замечается и тогда, когда новый файл успел стать длиннее прочитанного: при изменении
+// размера или времени модификации сверяется начало файла.
+// Если файл заменён новым (ротация), сначала дочитывается старый файл, затем новый читается с начала
+// как продолжение, без сброса.
+func (t *Tailer) Poll() (lines []string, reset bool, err error) {
+	if t.file == nil {
+		if err := t.open(); err != nil {
+			// Файл может ещё не существовать в начале гонки
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil, false, nil
+			}
+			return nil, false, err
+		}
+	}
+
+	info, err := os.Stat(t.path)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		// Старый файл переименован, а новый ещё не создан
+		lines, err := t.read()
+		return lines, false, err
+	case err != nil:
+		return nil, false, err
+	case !os.SameFile(t.info, info):
+		lines, err = t.read()
+		if err != nil {
+			return nil, false, err
+		}
+		t.file.Close()
+		if err := t.open(); err != nil {
+			return lines, false, err
+		}
+	case info.Size() < t.offset || t.changed(info) && t.rewritten():
+		if _, err := t.file.Seek(0, io.SeekStart); err != nil {
+			return nil, false, err
+		}
+		t.info, t.offset, t.head, t.partial, reset = info, 0, nil, nil, true
+	default:
+		t.info = info
+	}
+
+	more, err := t.read()
+	return append(lines, more...), reset, err
+}
+
+func (t *Tailer) Close() error {
+	if t.file == nil {
+		return nil
+	}
+	return t.file.Close()
+}
+
+func (t *Tailer) open() error {
+	file, err := os.Open(t.path)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+
+	t.file, t.info, t.offset, t.head, t.partial = file, info, 0, nil, nil
+	return nil
+}
+
+// changed сообщает, что с прошлого опроса у файла изменились размер или время модификации
+func (t *Tailer) changed(info os.FileInfo) bool {
+	return info.Size() != t.info.Size() || !info.ModTime().Equal(t.info.ModTime())
+}
+
+// rewritten сообщает, что начало файла отличается от прочитанного ранее
+func (t *Tailer) rewritten() bool {
+	buf := make([]byte, len(t.head))
+	n, err := t.file.ReadAt(buf, 0)
+	if err != nil && err != io.EOF {
+		return false
+	}
+	return !bytes.Equal(buf[:n], t.head)
+}
+
+func (t *Tailer) read() ([]string, error) {
+	data, err := io.ReadAll(t.file)
+	if err != nil {
+		return nil, err
+	}
+	t.offset += int64(len(data))
+	if n := min(headSize-len(t.head), len(data)); n > 0 {
+		t.head = append(t.head, data[:n]...)
+	}
+
+	data = append(t.partial, data...)
+	end := bytes.LastIndexByte(data, '\n')
+	if end < 0 {
+		t.partial = data
+		return nil, nil
+	}
+	t.partial = append([]byte(nil), data[end+1:]...)
+
+	var lines []string
+	for _, line := range bytes.Split(data[:end], []byte("\n")) {
+		line = bytes.TrimRight(line, "\r")
+		if len(bytes.TrimSpace(line)) > 0 {
+			lines = append(lines, string(line))
+		}
+	}
+	return lines, nil
+}
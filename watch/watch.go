@@ -0,0 +1,148 @@
+package watch
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"biathlon/config"
+	"biathlon/event"
+	"biathlon/race"
+)
+
+// Watcher следит за файлом событий во время гонки и после каждой порции новых событий
+// переписывает лог и итоговый отчёт
+type Watcher struct {
+	Config       *config.Config
+	EventsPath   string
+	OutputPrefix string
+	Interval     time.Duration
+	// Logf получает сообщения о пропущенных строках и перезапусках; может быть nil
+	Logf func(format string, args ...any)
+
+	tailer *Tailer
+	format event.Format
+	ctrl   *race.Controller
+}
+
+// Run опрашивает файл событий до отмены контекста
+func (w *Watcher) Run(ctx context.Context) error {
+	defer func() {
+		if w.tailer != nil {
+			w.tailer.Close()
+		}
+	}()
+
+	ticker := time.NewTicker(w.Interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := w.Step(); err != nil {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// Step обрабатывает новые строки файла событий и возвращает число применённых событий.
+// Выходные файлы переписываются, только если что-то изменилось.
+func (w *Watcher) Step() (int, error) {
+	if w.tailer == nil {
+		w.tailer = NewTailer(w.EventsPath)
+	}
+
+	lines, reset, err := w.tailer.Poll()
+	if err != nil {
+		return 0, fmt.Errorf("reading events: %w", err)
+	}
+
+	// Усечённый файл перезаписан с начала, поэтому гонка пересчитывается заново
+	if reset {
+		w.logf("%s was truncated, reprocessing from the beginning", w.EventsPath)
+	}
+	if w.ctrl == nil || reset {
+		w.ctrl = race.NewController(w.Config)
+	}
+
+	if len(lines) == 0 && !reset {
+		return 0, nil
+	}
+
+	applied := 0
+	for _, evt := range w.decode(lines) {
+		if err := w.ctrl.ProcessEvent(evt); err != nil {
+			return applied, fmt.Errorf("processing events: %w", err)
+		}
+		applied++
+	}
+
+	if err := w.write(); err != nil {
+		return applied, err
+	}
+	return applied, nil
+}
+
+// decode разбирает строки по одной: ошибочная строка пропускается, а не останавливает гонку
+func (w *Watcher) decode(lines []string) []event.Event {
+	if w.format == nil && len(lines) > 0 {
+		w.format = event.DetectFormat(w.EventsPath, []byte(lines[0]))
+	}
+
+	var events []event.Event
+	for _, line := range lines {
+		decoded, err := w.format.Decode(strings.NewReader(line))
+		if err != nil {
+			w.logf("skipping line %q: %v", line, err)
+			continue
+		}
+		events = append(events, decoded...)
+	}
+	return events
+}
+
+func (w *Watcher) write() error {
+	if err := WriteFileAtomic(w.OutputPrefix+"_log.txt", []byte(strings.Join(w.ctrl.OutputLog, "\n"))); err != nil {
+		return fmt.Errorf("writing output log: %w", err)
+	}
+	if err := WriteFileAtomic(w.OutputPrefix+"_report.txt", []byte(w.ctrl.GenerateReport())); err != nil {
+		return fmt.Errorf("writing final report: %w", err)
+	}
+	return nil
+}
+
+func (w *Watcher) logf(format string, args ...any) {
+	if w.Logf != nil {
+		w.Logf(format, args...)
+	}
+}
+
+// WriteFileAtomic пишет данные во временный файл рядом с целевым и переименовывает его,
+// чтобы читатели никогда не видели наполовину записанный файл
+func WriteFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(0644); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,142 @@
+package watch
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"biathlon/config"
+)
+
+func appendFile(t *testing.T, path, data string) {
+	t.Helper()
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if _, err := f.WriteString(data); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func poll(t *testing.T, tailer *Tailer) ([]string, bool) {
+	t.Helper()
+	lines, reset, err := tailer.Poll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return lines, reset
+}
+
+func TestTailer(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events")
+	tailer := NewTailer(path)
+	defer tailer.Close()
+
+	if lines, _ := poll(t, tailer); len(lines) != 0 {
+		t.Errorf("Missing file: got %v", lines)
+	}
+
+	appendFile(t, path, "a\nb\nc")
+	if lines, _ := poll(t, tailer); strings.Join(lines, ",") != "a,b" {
+		t.Errorf("Expected complete lines a,b, got %v", lines)
+	}
+
+	appendFile(t, path, "d\n")
+	if lines, _ := poll(t, tailer); strings.Join(lines, ",") != "cd" {
+		t.Errorf("Expected partial line to be completed, got %v", lines)
+	}
+
+	// Усечение: файл перезаписан с начала
+	if err := os.WriteFile(path, []byte("x\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if lines, reset := poll(t, tailer); !reset || strings.Join(lines, ",") != "x" {
+		t.Errorf("Expected reset with x after truncation, got %v, reset %v", lines, reset)
+	}
+
+	// Перезапись, после которой файл длиннее прочитанного
+	appendFile(t, path, "w\n")
+	if lines, _ := poll(t, tailer); strings.Join(lines, ",") != "w" {
+		t.Errorf("Expected w, got %v", lines)
+	}
+	if err := os.WriteFile(path, []byte("p\nq\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	appendFile(t, path, "r\n")
+	if lines, reset := poll(t, tailer); !reset || strings.Join(lines, ",") != "p,q,r" {
+		t.Errorf("Expected reset with p,q,r after truncation and growth, got %v, reset %v", lines, reset)
+	}
+
+	// Ротация: старый файл дочитывается, новый читается как продолжение
+	appendFile(t, path, "y\n")
+	if err := os.Rename(path, path+".1"); err != nil {
+		t.Fatal(err)
+	}
+	appendFile(t, path, "z\n")
+	if lines, reset := poll(t, tailer); reset || strings.Join(lines, ",") != "y,z" {
+		t.Errorf("Expected y,z without reset after rotation, got %v, reset %v", lines, reset)
+	}
+}
+
+func TestWatcherStep(t *testing.T) {
+	dir := t.TempDir()
+	eventsPath := filepath.Join(dir, "events")
+	w := &Watcher{
+		Config:       &config.Config{Laps: 1, LapLen: 3000, PenaltyLen: 150, FiringLines: 1, Start: "10:00:00.000", StartDelta: "00:01:00"},
+		EventsPath:   eventsPath,
+		OutputPrefix: filepath.Join(dir, "out"),
+	}
+
+	step := func(want int) {
+		t.Helper()
+		applied, err := w.Step()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if applied != want {
+			t.Errorf("Applied %d events, want %d", applied, want)
+		}
+	}
+	read := func(suffix string) string {
+		t.Helper()
+		data, err := os.ReadFile(w.OutputPrefix + suffix)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(data)
+	}
+
+	appendFile(t, eventsPath, "[09:00:00.000] 1 1\n[09:10:00.000] 2 1 10:00:00.000\n[10:00:01.000] 4")
+	step(2)
+	if log := read("_log.txt"); strings.Count(log, "\n") != 1 || strings.Contains(log, "has started") {
+		t.Errorf("Unexpected log after first batch:\n%s", log)
+	}
+
+	appendFile(t, eventsPath, " 1\nbroken line\n")
+	step(1)
+	if log := read("_log.txt"); !strings.HasSuffix(log, "The competitor(1) has started") {
+		t.Errorf("Expected start in log:\n%s", log)
+	}
+
+	// Без новых строк выходные файлы не переписываются
+	step(0)
+
+	if err := os.WriteFile(eventsPath, []byte("[09:00:00.000] 1 2\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	step(1)
+	if log := read("_log.txt"); log != "[09:00:00.000] The competitor(2) registered" {
+		t.Errorf("Expected reprocessed log after truncation, got:\n%s", log)
+	}
+	if report := read("_report.txt"); !strings.Contains(report, " 2 ") || strings.Contains(report, " 1 ") {
+		t.Errorf("Unexpected report after truncation:\n%s", report)
+	}
+
+	matches, _ := filepath.Glob(filepath.Join(dir, "*.tmp*"))
+	if len(matches) > 0 {
+		t.Errorf("Temporary files left behind: %v", matches)
+	}
+}